@@ -1,10 +1,14 @@
 package conf
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/iph0/merger"
 	mapstruct "github.com/mitchellh/mapstructure"
@@ -15,6 +19,10 @@ const (
 	decoderTagName = "conf"
 	refNameSep     = "."
 	keyStackCap    = 10
+	optionalMod    = "?"
+	requiredMod    = "!"
+	mountSep       = "="
+	fragmentSep    = "#"
 )
 
 // Processor loads configuration layers from different sources and merges them
@@ -28,6 +36,8 @@ type Processor struct {
 	seenNodes map[uintptr]struct{}
 	refs      map[string]reflect.Value
 	root      reflect.Value
+	key       []byte
+	source    loadedLayer
 }
 
 var (
@@ -35,10 +45,13 @@ var (
 	includeKey  = reflect.ValueOf("$include")
 	underlayKey = reflect.ValueOf("$underlay")
 	overlayKey  = reflect.ValueOf("$overlay")
+	secretKey   = reflect.ValueOf("$secret")
 
 	nameKey         = reflect.ValueOf("name")
 	firstDefinedKey = reflect.ValueOf("firstDefined")
 	defaultKey      = reflect.ValueOf("default")
+
+	durationType = reflect.TypeOf(time.Duration(0))
 )
 
 // ProcessorConfig is a structure with configuration parameters for configuration
@@ -51,6 +64,11 @@ type ProcessorConfig struct {
 	// DisableProcessing disables expansion of references and processing of
 	// directives.
 	DisableProcessing bool
+
+	// KeyProvider specifies provider of encryption key, that is used to decrypt
+	// encrypted values. Encryption key is requested once per loading of
+	// configuration tree and only if encrypted values are found.
+	KeyProvider KeyProvider
 }
 
 // Loader is an interface for configuration loaders.
@@ -58,12 +76,38 @@ type Loader interface {
 	Load(string) ([]any, error)
 }
 
+// RelativeLoader is an interface for configuration loaders, that can resolve
+// configuration locators relative to the origin of the configuration layer
+// with $include directive. The method LoadRelative is called instead of Load
+// method, if the including layer was loaded by the same loader and has an
+// origin.
+type RelativeLoader interface {
+	Loader
+	LoadRelative(locator, origin string) ([]any, error)
+}
+
+// Layer is a configuration layer with an origin. Configuration loaders can
+// return layers of this type to attach the origin, like the path of the
+// configuration file, to configuration layers. The origin is passed to
+// RelativeLoader to resolve relative locators in $include directives of the
+// layer.
+type Layer struct {
+	Value  any
+	Origin string
+}
+
 // M type is a convenient alias for a map[string]any map.
 type M = map[string]any
 
 // A type is a convenient alias for a []any slice.
 type A = []any
 
+type loadedLayer struct {
+	value  any
+	loader string
+	origin string
+}
+
 type keyStack struct {
 	s []string
 }
@@ -97,9 +141,11 @@ func NewProcessor(config ProcessorConfig) *Processor {
 //   - single values are converted to slices if required. Each element also can
 //     be converted. For example: "4" can become []int{4} if the target type is
 //     an int slice.
+//   - strings to time.Duration (see time.ParseDuration)
 func Decode(configRaw, config any) error {
 	decoder, err := mapstruct.NewDecoder(
 		&mapstruct.DecoderConfig{
+			DecodeHook:       decodeDuration,
 			WeaklyTypedInput: true,
 			Result:           config,
 			TagName:          decoderTagName,
@@ -119,6 +165,26 @@ func Decode(configRaw, config any) error {
 	return nil
 }
 
+// decodeDuration converts strings like "1m30s" to durations. Empty strings and
+// strings with plain integers are left to weak conversion to integers.
+func decodeDuration(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String || to != durationType {
+		return data, nil
+	}
+
+	str := reflect.ValueOf(data).String()
+
+	if str == "" {
+		return data, nil
+	}
+
+	if _, err := strconv.ParseInt(str, 0, 64); err == nil {
+		return data, nil
+	}
+
+	return time.ParseDuration(str)
+}
+
 // Load method loads configuration tree using configuration locators. The merge
 // priority of loaded configuration layers depends on the order of configuration
 // locators. Layers loaded by rightmost locator have highest priority.
@@ -127,6 +193,10 @@ func (p *Processor) Load(locators ...any) (M, error) {
 		panic(fmt.Errorf("%s: no configuration locators specified", errPref))
 	}
 
+	defer func() {
+		p.key = nil
+	}()
+
 	layers, err := p.load(locators)
 
 	if err != nil {
@@ -135,22 +205,22 @@ func (p *Processor) Load(locators ...any) (M, error) {
 
 	if !p.config.DisableProcessing {
 		for i, layer := range layers {
-			layer, err := p.processLayer(layer)
+			value, err := p.processLayer(layer)
 
 			if err != nil {
 				return nil, err
-			} else if layer == nil {
+			} else if value == nil {
 				continue
 			}
 
-			layers[i] = layer
+			layers[i].value = value
 		}
 	}
 
 	var config any
 
 	for _, layer := range layers {
-		config = merger.Merge(config, layer)
+		config = merger.Merge(config, layer.value)
 	}
 
 	if config == nil {
@@ -175,57 +245,188 @@ func (p *Processor) Load(locators ...any) (M, error) {
 			"but got \"%T\"", errPref, config)
 }
 
-func (p *Processor) load(locators []any) ([]any, error) {
-	var allLayers []any
+func (p *Processor) load(locators []any) ([]loadedLayer, error) {
+	var allLayers []loadedLayer
 
 	for _, locator := range locators {
 		switch loc := locator.(type) {
 		case M:
-			allLayers = append(allLayers, loc)
+			allLayers = append(allLayers, loadedLayer{value: loc})
 		case string:
-			if loc == "" {
-				return nil, fmt.Errorf("%s: empty configuration locator specified",
-					errPref)
+			layers, err := p.loadLocator(loc)
+
+			if err != nil {
+				return nil, err
 			}
 
-			tokens := strings.SplitN(loc, ":", 2)
+			allLayers = append(allLayers, layers...)
+		default:
+			return nil,
+				fmt.Errorf("%s: configuration locator must be of type \"string\" or "+
+					"\"map[string]any\", but got \"%T\"", errPref, locator)
+		}
+	}
 
-			if len(tokens) < 2 || tokens[0] == "" {
-				return nil, fmt.Errorf("%s: missing loader name in configuration locator",
-					errPref)
-			}
+	return allLayers, nil
+}
 
-			loaderName := tokens[0]
-			locValue := tokens[1]
+func (p *Processor) loadLocator(locator string) ([]loadedLayer, error) {
+	if locator == "" {
+		return nil, fmt.Errorf("%s: empty configuration locator specified",
+			errPref)
+	}
 
-			if loader, ok := p.config.Loaders[loaderName]; ok {
-				layers, err := loader.Load(locValue)
+	tokens := strings.SplitN(locator, ":", 2)
 
-				if err != nil {
-					return nil, err
-				} else if len(layers) == 0 {
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("%s: missing loader name in configuration locator",
+			errPref)
+	}
+
+	loaderName := tokens[0]
+	locValue := tokens[1]
+	var mountPath, fragment string
+	var optional, required bool
+
+	if i := strings.LastIndex(loaderName, mountSep); i != -1 {
+		mountPath = loaderName[:i]
+		loaderName = loaderName[i+1:]
+	}
+
+	if strings.HasSuffix(loaderName, optionalMod) {
+		loaderName = strings.TrimSuffix(loaderName, optionalMod)
+		optional = true
+	} else if strings.HasSuffix(loaderName, requiredMod) {
+		loaderName = strings.TrimSuffix(loaderName, requiredMod)
+		required = true
+	}
+
+	if loaderName == "" {
+		return nil, fmt.Errorf("%s: missing loader name in configuration locator",
+			errPref)
+	}
+
+	loader, ok := p.config.Loaders[loaderName]
+
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown loader: %s", errPref, loaderName)
+	}
+
+	var layers []any
+	var err error
+
+	// The part after "#" symbol is treated as the fragment only if it is a valid
+	// parameter path and the part before "#" symbol loads at least one layer.
+	// Otherwise "#" symbol is a part of the locator value, like in file names.
+	if i := strings.LastIndex(locValue, fragmentSep); i != -1 &&
+		isParamPath(locValue[i+1:]) {
+
+		layers, err = p.loadValue(loader, loaderName, locValue[:i])
+
+		if err == nil && hasLayers(layers) {
+			fragment = locValue[i+1:]
+		} else if err == nil || errors.Is(err, fs.ErrNotExist) {
+			layers, err = p.loadValue(loader, loaderName, locValue)
+		}
+	} else {
+		layers, err = p.loadValue(loader, loaderName, locValue)
+	}
+
+	if err != nil {
+		if optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var loadedLayers []loadedLayer
+
+	for _, layer := range layers {
+		var origin string
+
+		if lyr, ok := layer.(Layer); ok {
+			layer = lyr.Value
+			origin = lyr.Origin
+		}
+
+		if fragment != "" {
+			var err error
+			layer, err = Config{root: layer}.Get(fragment)
+
+			if err != nil {
+				var nfErr *NotFoundError
+
+				if errors.As(err, &nfErr) {
 					continue
 				}
 
-				allLayers = append(allLayers, layers...)
-			} else {
-				return nil, fmt.Errorf("%s: unknown loader: %s", errPref, loaderName)
+				return nil, err
 			}
-		default:
-			return nil,
-				fmt.Errorf("%s: configuration locator must be of type \"string\" or "+
-					"\"map[string]any\", but got \"%T\"", errPref, locator)
 		}
+
+		if layer == nil {
+			continue
+		}
+
+		if mountPath != "" {
+			keys := strings.Split(mountPath, refNameSep)
+
+			for i := len(keys) - 1; i >= 0; i-- {
+				layer = M{strings.Trim(keys[i], " "): layer}
+			}
+		}
+
+		loadedLayers = append(loadedLayers,
+			loadedLayer{
+				value:  layer,
+				loader: loaderName,
+				origin: origin,
+			},
+		)
 	}
 
-	return allLayers, nil
+	if required && len(loadedLayers) == 0 {
+		return nil, fmt.Errorf("%s: no configuration layers loaded by required "+
+			"configuration locator: %s", errPref, locator)
+	}
+
+	return loadedLayers, nil
 }
 
-func (p *Processor) processLayer(layer any) (any, error) {
+// loadValue method loads configuration layers by the locator value. Relative
+// locator values are resolved against the origin of the current layer, if the
+// loader supports it.
+func (p *Processor) loadValue(loader Loader, loaderName,
+	locValue string) ([]any, error) {
+
+	relLoader, ok := loader.(RelativeLoader)
+
+	if ok && p.source.loader == loaderName && p.source.origin != "" {
+		return relLoader.LoadRelative(locValue, p.source.origin)
+	}
+
+	return loader.Load(locValue)
+}
+
+func (p *Processor) processLayer(layer loadedLayer) (any, error) {
 	p.beforeProcess()
 	defer p.afterProcess()
 
-	lyr := reflect.ValueOf(layer)
+	return p.includeLayer(layer)
+}
+
+// includeLayer method processes $include directives of the layer using the
+// origin of the layer to resolve relative locators.
+func (p *Processor) includeLayer(layer loadedLayer) (any, error) {
+	source := p.source
+	p.source = layer
+
+	defer func() {
+		p.source = source
+	}()
+
+	lyr := reflect.ValueOf(layer.value)
 	lyr, err := p.processIncludes(lyr)
 
 	if err != nil {
@@ -337,6 +538,10 @@ func (p *Processor) processSlice(s reflect.Value, f processFunc) error {
 			return err
 		}
 
+		if !node.IsValid() {
+			node = reflect.Zero(s.Type().Elem())
+		}
+
 		s.Index(i).Set(node)
 
 		p.keyStack.Pop()
@@ -364,11 +569,22 @@ func (p *Processor) applyInclude(node reflect.Value) (reflect.Value, error) {
 func (p *Processor) applyDirectives(node reflect.Value) (reflect.Value, error) {
 	switch node.Kind() {
 	case reflect.String:
-		str := node.Interface().(string)
+		if isSecret(node) {
+			return node, nil
+		}
+
+		str := node.String()
+
+		if isEncrypted(str) {
+			return p.decryptValue(str)
+		}
+
 		return p.expandRefs(str)
 	case reflect.Map:
 		if ref := node.MapIndex(refKey); ref.IsValid() {
 			return p.resolveRef(ref)
+		} else if secret := node.MapIndex(secretKey); secret.IsValid() {
+			return p.resolveSecret(secret)
 		} else {
 			if names := node.MapIndex(underlayKey); names.IsValid() {
 				var err error
@@ -439,7 +655,13 @@ func (p *Processor) includeSection(locators reflect.Value) (reflect.Value, error
 	var config any
 
 	for _, layer := range layers {
-		config = merger.Merge(config, layer)
+		value, err := p.includeLayer(layer)
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		config = merger.Merge(config, value)
 	}
 
 	return reflect.ValueOf(config), nil
@@ -530,6 +752,45 @@ func (p *Processor) resolveRef(ref reflect.Value) (reflect.Value, error) {
 	return reflect.Value{}, nil
 }
 
+func (p *Processor) resolveSecret(secret reflect.Value) (reflect.Value, error) {
+	secret, err := p.processDirectives(secret)
+
+	if err != nil {
+		return reflect.Value{}, err
+	} else if !secret.IsValid() {
+		return secret, nil
+	}
+
+	return reflect.ValueOf(markSecret(secret.Interface())), nil
+}
+
+func (p *Processor) decryptValue(value string) (reflect.Value, error) {
+	if p.key == nil {
+		if p.config.KeyProvider == nil {
+			return reflect.Value{}, fmt.Errorf("%s: no key provider specified to "+
+				"decrypt encrypted value at node: %s", errPref, p.keyStack)
+		}
+
+		key, err := p.config.KeyProvider.Key()
+
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: can't get encryption key: %s",
+				errPref, err)
+		}
+
+		p.key = key
+	}
+
+	plain, err := decrypt(value, p.key)
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: can't decrypt encrypted value at "+
+			"node: %s: %s", errPref, p.keyStack, err)
+	}
+
+	return reflect.ValueOf(Secret(plain)), nil
+}
+
 func (p *Processor) mergeLayers(directiveKey reflect.Value, node reflect.Value,
 	names reflect.Value) (reflect.Value, error) {
 
@@ -604,6 +865,7 @@ func (p *Processor) mergeLayers(directiveKey reflect.Value, node reflect.Value,
 
 func (p *Processor) expandRefs(str string) (reflect.Value, error) {
 	var res string
+	var sensitive bool
 	runes := []rune(str)
 	runesLen := len(runes)
 	i, j := 0, 0
@@ -618,7 +880,7 @@ func (p *Processor) expandRefs(str string) (reflect.Value, error) {
 				k++
 			}
 
-			if runes[j+k] == '{' {
+			if j+k < runesLen && runes[j+k] == '{' {
 				res += string(runes[i:j])
 
 				for i, j = j, j+k+1; j < runesLen; j++ {
@@ -635,7 +897,10 @@ func (p *Processor) expandRefs(str string) (reflect.Value, error) {
 									return reflect.Value{}, err
 								}
 
-								if node.IsValid() {
+								if isSecret(node) {
+									res += node.String()
+									sensitive = true
+								} else if node.IsValid() {
 									res += fmt.Sprintf("%v", node.Interface())
 								}
 							} else {
@@ -658,6 +923,10 @@ func (p *Processor) expandRefs(str string) (reflect.Value, error) {
 
 	res += string(runes[i:j])
 
+	if sensitive {
+		return reflect.ValueOf(Secret(res)), nil
+	}
+
 	return reflect.ValueOf(res), nil
 }
 
@@ -758,6 +1027,39 @@ func (p *Processor) resolveNode(name string) (reflect.Value, error) {
 	return node, nil
 }
 
+// hasLayers checks, that at least one of loaded layers is not empty.
+func hasLayers(layers []any) bool {
+	for _, layer := range layers {
+		if lyr, ok := layer.(Layer); ok {
+			layer = lyr.Value
+		}
+
+		if layer != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isParamPath checks, that the string is a dotted path of parameter names,
+// which consist of letters, digits, "_" and "-" symbols.
+func isParamPath(path string) bool {
+	for _, key := range strings.Split(path, refNameSep) {
+		if key == "" {
+			return false
+		}
+
+		for _, r := range key {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 func strip(value reflect.Value) reflect.Value {
 	if value.Kind() == reflect.Interface {
 		return value.Elem()
@@ -1,10 +1,14 @@
 package conf_test
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/iph0/conf/v2"
 )
@@ -222,6 +226,309 @@ func TestDisableProcessing(t *testing.T) {
 	}
 }
 
+func TestTrailingDollars(t *testing.T) {
+	configProc := conf.NewProcessor(conf.ProcessorConfig{})
+
+	tConfig, err := configProc.Load(
+		conf.M{
+			"paramA": "x$$",
+			"paramB": "x$",
+		},
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": "x$$",
+		"paramB": "x$",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
+func TestLocatorModifiers(t *testing.T) {
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"map": &mapLoader{
+					m: conf.M{
+						"foo": conf.M{
+							"paramA": "foo:valA",
+							"paramB": conf.M{"$include": "map?:moo"},
+						},
+					},
+				},
+
+				"none": &errLoader{err: fs.ErrNotExist},
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load(
+		"map!:foo",
+		"map?:bar",
+		"none?:bar",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": "foo:valA",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+
+	t.Run("required_locator",
+		func(t *testing.T) {
+			_, err := configProc.Load("map!:bar")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "no configuration layers loaded by required configuration locator: map!:bar") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("required_include",
+		func(t *testing.T) {
+			_, err := configProc.Load(conf.M{"$include": conf.A{"map!:bar"}})
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "no configuration layers loaded") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("not_exist_error",
+		func(t *testing.T) {
+			_, err := configProc.Load("none:bar")
+
+			if !errors.Is(err, fs.ErrNotExist) {
+				t.Error("unexpected error returned:", err)
+			}
+		},
+	)
+
+	t.Run("missing_loader",
+		func(t *testing.T) {
+			_, err := configProc.Load("?:foo")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "missing loader name") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func TestMountLocators(t *testing.T) {
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"map": &mapLoader{
+					m: conf.M{
+						"foo": conf.M{
+							"paramA": "foo:valA",
+
+							"paramB": conf.M{
+								"paramBA": "foo:valBA",
+								"paramBB": conf.A{"foo:valBBA", "foo:valBBB"},
+							},
+						},
+
+						"bar#1": conf.M{
+							"paramG": "bar:valG",
+						},
+					},
+				},
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load(
+		"paramC.paramCA=map:foo",
+		"map!:bar#1",
+		"paramD=map:foo#paramB.paramBB.1",
+		"map:foo#paramB",
+		"paramE=map?:foo#paramX",
+		conf.M{
+			"paramF": conf.M{"$include": "paramFA=map!:foo#paramA"},
+		},
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramC": conf.M{
+			"paramCA": conf.M{
+				"paramA": "foo:valA",
+
+				"paramB": conf.M{
+					"paramBA": "foo:valBA",
+					"paramBB": conf.A{"foo:valBBA", "foo:valBBB"},
+				},
+			},
+		},
+
+		"paramD":  "foo:valBBB",
+		"paramBA": "foo:valBA",
+		"paramBB": conf.A{"foo:valBBA", "foo:valBBB"},
+
+		"paramF": conf.M{
+			"paramFA": "foo:valA",
+		},
+
+		"paramG": "bar:valG",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+
+	t.Run("required_fragment",
+		func(t *testing.T) {
+			_, err := configProc.Load("map!:foo#paramX")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "no configuration layers loaded") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_fragment_index",
+		func(t *testing.T) {
+			_, err := configProc.Load("map:foo#paramB.paramBB.foo")
+			var tErr *conf.TypeError
+
+			if !errors.As(err, &tErr) {
+				t.Error("unexpected error returned:", err)
+			} else if strings.Index(err.Error(), "can not be converted to index") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func TestRelativeLocators(t *testing.T) {
+	treeLdr := &treeLoader{
+		m: conf.M{
+			"app/main": conf.M{
+				"paramA": "main:valA",
+				"paramB": conf.M{"$include": "tree:./db/main"},
+				"paramC": conf.M{"$include": "tree:opts"},
+				"paramD": conf.M{"$include": "map:./db/opts"},
+			},
+
+			"app/db/main": conf.M{
+				"paramBA": "db:valBA",
+				"paramBB": conf.M{"$include": "tree:./opts"},
+			},
+
+			"app/db/opts": conf.M{
+				"paramBBA": "app/db/opts:valBBA",
+			},
+
+			"opts": conf.M{
+				"paramCA": "opts:valCA",
+			},
+		},
+	}
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"tree": treeLdr,
+
+				"map": &mapLoader{
+					m: conf.M{
+						"./db/opts": conf.M{
+							"paramDA": "map:valDA",
+						},
+					},
+				},
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load("tree:app/main")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": "main:valA",
+
+		"paramB": conf.M{
+			"paramBA": "db:valBA",
+
+			"paramBB": conf.M{
+				"paramBBA": "app/db/opts:valBBA",
+			},
+		},
+
+		"paramC": conf.M{
+			"paramCA": "opts:valCA",
+		},
+
+		"paramD": conf.M{
+			"paramDA": "map:valDA",
+		},
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
+func TestNilSliceElements(t *testing.T) {
+	configProc := conf.NewProcessor(conf.ProcessorConfig{})
+
+	tConfig, err := configProc.Load(
+		conf.M{
+			"paramA": conf.A{nil, "valAB", nil},
+		},
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": conf.A{nil, "valAB", nil},
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
 func TestDecode(t *testing.T) {
 	type testConfig struct {
 		ParamA string `conf:"test_paramA"`
@@ -259,6 +566,39 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeDuration(t *testing.T) {
+	type testConfig struct {
+		ParamA time.Duration
+		ParamB time.Duration
+		ParamC time.Duration
+	}
+
+	configRaw := conf.M{
+		"paramA": "1m30s",
+		"paramB": "1000",
+		"paramC": 2000,
+	}
+
+	var tConfig testConfig
+	err := conf.Decode(configRaw, &tConfig)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := testConfig{
+		ParamA: 90 * time.Second,
+		ParamB: 1000,
+		ParamC: 2000,
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
 func TestPanic(t *testing.T) {
 	t.Run("no_locators",
 		func(t *testing.T) {
@@ -807,3 +1147,31 @@ type mapLoader struct {
 func (l *mapLoader) Load(key string) ([]any, error) {
 	return []any{l.m[key]}, nil
 }
+
+type errLoader struct {
+	err error
+}
+
+// Load method returns an error.
+func (l *errLoader) Load(string) ([]any, error) {
+	return nil, l.err
+}
+
+type treeLoader struct {
+	m conf.M
+}
+
+// Load method loads configuration layer from a map and attaches the key as the
+// origin.
+func (l *treeLoader) Load(key string) ([]any, error) {
+	return []any{conf.Layer{Value: l.m[key], Origin: key}}, nil
+}
+
+// LoadRelative method resolves keys started with "./" relative to the origin.
+func (l *treeLoader) LoadRelative(key, origin string) ([]any, error) {
+	if strings.HasPrefix(key, "./") {
+		key = path.Join(path.Dir(origin), key)
+	}
+
+	return l.Load(key)
+}
@@ -0,0 +1,205 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config type is a wrapper for a configuration tree, that provides typed access
+// to configuration parameters by path. Path is a list of parameter names and
+// array indices separated by dots, the same as in references. For example:
+// "db.connectors.stat.port" or "myapp.mediaDirs.0". Empty path points to the
+// root of the wrapped configuration tree. Typed accessors make the same
+// conversions as Decode function.
+type Config struct {
+	root   any
+	prefix string
+}
+
+// NotFoundError is returned by Config methods, if configuration parameter is
+// not found or has nil value.
+type NotFoundError struct {
+	Path string
+}
+
+// TypeError is returned by Config methods, if configuration parameter can not
+// be converted to the requested type, or the path contains a non-numeric index
+// of an array.
+type TypeError struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// NewConfig method creates new Config instance for a configuration tree.
+func NewConfig(tree M) Config {
+	return Config{
+		root: tree,
+	}
+}
+
+// Get method returns raw value of configuration parameter.
+func (c Config) Get(path string) (any, error) {
+	fullPath := c.fullPath(path)
+
+	if path == "" {
+		if c.root == nil {
+			return nil, &NotFoundError{Path: fullPath}
+		}
+
+		return c.root, nil
+	}
+
+	node := reflect.ValueOf(c.root)
+	keys := strings.Split(path, refNameSep)
+
+	for _, keyStr := range keys {
+		node = strip(node)
+		keyStr = strings.Trim(keyStr, " ")
+
+		switch node.Kind() {
+		case reflect.Map:
+			node = node.MapIndex(reflect.ValueOf(keyStr))
+		case reflect.Slice:
+			j, err := strconv.Atoi(keyStr)
+
+			if err != nil {
+				return nil, &TypeError{
+					Path:     fullPath,
+					Expected: "index",
+					Actual:   fmt.Sprintf("%T", node.Interface()),
+				}
+			} else if j < 0 || j >= node.Len() {
+				return nil, &NotFoundError{Path: fullPath}
+			}
+
+			node = node.Index(j)
+		default:
+			return nil, &NotFoundError{Path: fullPath}
+		}
+
+		if !node.IsValid() {
+			return nil, &NotFoundError{Path: fullPath}
+		}
+	}
+
+	node = strip(node)
+
+	if !node.IsValid() {
+		return nil, &NotFoundError{Path: fullPath}
+	}
+
+	return node.Interface(), nil
+}
+
+// String method returns value of configuration parameter as a string.
+func (c Config) String(path string) (string, error) {
+	var value string
+	err := c.decodeValue(path, &value, "string")
+
+	return value, err
+}
+
+// Int method returns value of configuration parameter as an integer.
+func (c Config) Int(path string) (int, error) {
+	var value int
+	err := c.decodeValue(path, &value, "int")
+
+	return value, err
+}
+
+// Bool method returns value of configuration parameter as a boolean.
+func (c Config) Bool(path string) (bool, error) {
+	var value bool
+	err := c.decodeValue(path, &value, "bool")
+
+	return value, err
+}
+
+// Duration method returns value of configuration parameter as a duration.
+// Strings are parsed by time.ParseDuration function, numbers are treated as
+// nanoseconds.
+func (c Config) Duration(path string) (time.Duration, error) {
+	var value time.Duration
+	err := c.decodeValue(path, &value, "duration")
+
+	return value, err
+}
+
+// Slice method returns value of configuration parameter as a slice. Single
+// values are converted to slices with one element.
+func (c Config) Slice(path string) (A, error) {
+	var value A
+	err := c.decodeValue(path, &value, "slice")
+
+	return value, err
+}
+
+// Sub method returns Config instance for a configuration section. If
+// configuration section is not found, accessors of returned instance return
+// NotFoundError.
+func (c Config) Sub(path string) Config {
+	node, _ := c.Get(path)
+
+	return Config{
+		root:   node,
+		prefix: c.fullPath(path),
+	}
+}
+
+// Decode method decodes configuration parameter into a structure or any other
+// value using Decode function.
+func (c Config) Decode(path string, config any) error {
+	node, err := c.Get(path)
+
+	if err != nil {
+		return err
+	}
+
+	return Decode(node, config)
+}
+
+func (c Config) decodeValue(path string, value any, typeName string) error {
+	node, err := c.Get(path)
+
+	if err != nil {
+		return err
+	}
+
+	err = Decode(node, value)
+
+	if err != nil {
+		return &TypeError{
+			Path:     c.fullPath(path),
+			Expected: typeName,
+			Actual:   fmt.Sprintf("%T", node),
+		}
+	}
+
+	return nil
+}
+
+func (c Config) fullPath(path string) string {
+	if c.prefix == "" {
+		return path
+	} else if path == "" {
+		return c.prefix
+	}
+
+	return c.prefix + refNameSep + path
+}
+
+// Error method returns error message.
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s: parameter not found: %s", errPref, e.Path)
+}
+
+// Error method returns error message. The message does not contain the value of
+// configuration parameter, because it can be sensitive.
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("%s: parameter %s can not be converted to %s from \"%s\"",
+		errPref, e.Path, e.Expected, e.Actual)
+}
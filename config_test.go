@@ -0,0 +1,208 @@
+package conf_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/iph0/conf/v2"
+)
+
+func TestConfig(t *testing.T) {
+	config := conf.NewConfig(
+		conf.M{
+			"db": conf.M{
+				"connectors": conf.M{
+					"stat": conf.M{
+						"host":     "stat-master.mydb.com",
+						"port":     "5432",
+						"password": conf.Secret("stat_writer_pass"),
+						"timeout":  "1m30s",
+						"debug":    1,
+						"hosts":    conf.A{"stat-slave1.mydb.com", "stat-slave2.mydb.com"},
+					},
+				},
+			},
+		},
+	)
+
+	t.Run("get",
+		func(t *testing.T) {
+			value, err := config.Get("db.connectors.stat.hosts.1")
+
+			if err != nil {
+				t.Error(err)
+			} else if value != "stat-slave2.mydb.com" {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("string",
+		func(t *testing.T) {
+			value, err := config.String("db.connectors.stat.password")
+
+			if err != nil {
+				t.Error(err)
+			} else if value != "stat_writer_pass" {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("int",
+		func(t *testing.T) {
+			value, err := config.Int("db.connectors.stat.port")
+
+			if err != nil {
+				t.Error(err)
+			} else if value != 5432 {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("bool",
+		func(t *testing.T) {
+			value, err := config.Bool("db.connectors.stat.debug")
+
+			if err != nil {
+				t.Error(err)
+			} else if !value {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("duration",
+		func(t *testing.T) {
+			value, err := config.Duration("db.connectors.stat.timeout")
+
+			if err != nil {
+				t.Error(err)
+			} else if value != 90*time.Second {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("slice",
+		func(t *testing.T) {
+			value, err := config.Slice("db.connectors.stat.host")
+			eValue := conf.A{"stat-master.mydb.com"}
+
+			if err != nil {
+				t.Error(err)
+			} else if !reflect.DeepEqual(value, eValue) {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("sub",
+		func(t *testing.T) {
+			value, err := config.Sub("db.connectors").Sub("stat").String("host")
+
+			if err != nil {
+				t.Error(err)
+			} else if value != "stat-master.mydb.com" {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+
+	t.Run("decode",
+		func(t *testing.T) {
+			type dbConnector struct {
+				Host     string
+				Port     int
+				Password conf.Secret
+				Timeout  time.Duration
+			}
+
+			var value dbConnector
+			err := config.Decode("db.connectors.stat", &value)
+
+			eValue := dbConnector{
+				Host:     "stat-master.mydb.com",
+				Port:     5432,
+				Password: "stat_writer_pass",
+				Timeout:  90 * time.Second,
+			}
+
+			if err != nil {
+				t.Error(err)
+			} else if !reflect.DeepEqual(value, eValue) {
+				t.Errorf("unexpected value returned: %v", value)
+			}
+		},
+	)
+}
+
+func TestConfigErrors(t *testing.T) {
+	config := conf.NewConfig(
+		conf.M{
+			"db": conf.M{
+				"host":     "stat-master.mydb.com",
+				"password": conf.Secret("stat_writer_pass"),
+				"hosts":    conf.A{"stat-slave1.mydb.com"},
+			},
+		},
+	)
+
+	t.Run("not_found",
+		func(t *testing.T) {
+			_, err := config.Sub("db").String("port")
+			var nfErr *conf.NotFoundError
+
+			if !errors.As(err, &nfErr) {
+				t.Error("unexpected error returned:", err)
+			} else if nfErr.Path != "db.port" {
+				t.Error("unexpected path in error:", nfErr.Path)
+			}
+		},
+	)
+
+	t.Run("index_out_of_range",
+		func(t *testing.T) {
+			_, err := config.String("db.hosts.1")
+			var nfErr *conf.NotFoundError
+
+			if !errors.As(err, &nfErr) {
+				t.Error("unexpected error returned:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_index",
+		func(t *testing.T) {
+			_, err := config.String("db.hosts.foo")
+			var tErr *conf.TypeError
+
+			if !errors.As(err, &tErr) {
+				t.Error("unexpected error returned:", err)
+			} else if tErr.Path != "db.hosts.foo" || tErr.Expected != "index" ||
+				tErr.Actual != "[]interface {}" {
+
+				t.Errorf("unexpected error returned: %+v", tErr)
+			}
+		},
+	)
+
+	t.Run("wrong_type",
+		func(t *testing.T) {
+			_, err := config.Int("db.password")
+			var tErr *conf.TypeError
+
+			if !errors.As(err, &tErr) {
+				t.Error("unexpected error returned:", err)
+			} else if tErr.Path != "db.password" || tErr.Expected != "int" {
+				t.Errorf("unexpected error returned: %+v", tErr)
+			} else if strings.Contains(err.Error(), "stat_writer_pass") {
+				t.Error("secret value leaked:", err)
+			}
+		},
+	)
+}
@@ -0,0 +1,177 @@
+package conf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	encPrefix  = "ENC[AES256_GCM,"
+	encSuffix  = "]"
+	encKeySize = 32
+)
+
+// KeyProvider is an interface for providers of encryption keys, that are used
+// by configuration processor to decrypt encrypted values.
+type KeyProvider interface {
+	Key() ([]byte, error)
+}
+
+// KeyProviderFunc type is an adapter to allow the use of ordinary functions as
+// key providers.
+type KeyProviderFunc func() ([]byte, error)
+
+// Key method calls f().
+func (f KeyProviderFunc) Key() ([]byte, error) {
+	return f()
+}
+
+// KeyFile function returns key provider, that reads encryption key from a file.
+// The file must contain 32 bytes long key encoded in base64.
+func KeyFile(path string) KeyProvider {
+	return KeyProviderFunc(
+		func() ([]byte, error) {
+			data, err := os.ReadFile(path)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return parseKey(data)
+		},
+	)
+}
+
+// KeyEnv function returns key provider, that reads encryption key from an
+// environment variable. The variable must contain 32 bytes long key encoded in
+// base64.
+func KeyEnv(name string) KeyProvider {
+	return KeyProviderFunc(
+		func() ([]byte, error) {
+			data, ok := os.LookupEnv(name)
+
+			if !ok {
+				return nil, fmt.Errorf("environment variable %s not set", name)
+			}
+
+			return parseKey([]byte(data))
+		},
+	)
+}
+
+// Encrypt function encrypts a string value with AES-256 in GCM mode and returns
+// it in the form, that can be placed in configuration files:
+//
+//	ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]
+func Encrypt(value string, key []byte) (string, error) {
+	aead, err := newAEAD(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nil, iv, []byte(value), nil)
+	dataLen := len(sealed) - aead.Overhead()
+	enc := base64.StdEncoding
+
+	return fmt.Sprintf("%sdata:%s,iv:%s,tag:%s,type:str%s", encPrefix,
+		enc.EncodeToString(sealed[:dataLen]), enc.EncodeToString(iv),
+		enc.EncodeToString(sealed[dataLen:]), encSuffix), nil
+}
+
+func decrypt(value string, key []byte) (string, error) {
+	aead, err := newAEAD(key)
+
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.TrimSuffix(strings.TrimPrefix(value, encPrefix), encSuffix)
+	var data, iv, tag []byte
+
+	for _, field := range strings.Split(fields, ",") {
+		tokens := strings.SplitN(field, ":", 2)
+
+		if len(tokens) < 2 {
+			return "", fmt.Errorf("invalid field in encrypted value: %s", field)
+		}
+
+		var err error
+
+		switch tokens[0] {
+		case "data":
+			data, err = base64.StdEncoding.DecodeString(tokens[1])
+		case "iv":
+			iv, err = base64.StdEncoding.DecodeString(tokens[1])
+		case "tag":
+			tag, err = base64.StdEncoding.DecodeString(tokens[1])
+		case "type":
+			if tokens[1] != "str" {
+				err = fmt.Errorf("unsupported type of encrypted value: %s", tokens[1])
+			}
+		default:
+			err = fmt.Errorf("unknown field in encrypted value: %s", tokens[0])
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(iv) != aead.NonceSize() || len(tag) != aead.Overhead() {
+		return "", errors.New("invalid size of iv or tag in encrypted value")
+	}
+
+	plain, err := aead.Open(nil, iv, append(data, tag...), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encPrefix) &&
+		strings.HasSuffix(value, encSuffix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != encKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes long, but got %d",
+			encKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func parseKey(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	key := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(key, data)
+
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be encoded in base64: %s", err)
+	}
+
+	return key[:n], nil
+}
@@ -0,0 +1,172 @@
+package conf_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+var (
+	testKey  = []byte("0123456789abcdef0123456789abcdef")
+	otherKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestDecrypt(t *testing.T) {
+	encValue, err := conf.Encrypt("stat_writer_pass", testKey)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if !strings.HasPrefix(encValue, "ENC[AES256_GCM,data:") {
+		t.Errorf("unexpected encrypted value: %s", encValue)
+		return
+	}
+
+	layer := conf.M{
+		"db": conf.M{
+			"username": "stat_writer",
+			"password": encValue,
+			"dsn":      "${db.username}:${db.password}",
+		},
+	}
+
+	eConfig := conf.M{
+		"db": conf.M{
+			"username": "stat_writer",
+			"password": conf.Secret("stat_writer_pass"),
+			"dsn":      conf.Secret("stat_writer:stat_writer_pass"),
+		},
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "conf.key")
+	keyStr := base64.StdEncoding.EncodeToString(testKey)
+	err = os.WriteFile(keyFile, []byte(keyStr+"\n"), 0600)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Setenv("TEST_CONF_KEY", keyStr)
+
+	keyProviders := map[string]conf.KeyProvider{
+		"func": conf.KeyProviderFunc(
+			func() ([]byte, error) {
+				return testKey, nil
+			},
+		),
+		"file": conf.KeyFile(keyFile),
+		"env":  conf.KeyEnv("TEST_CONF_KEY"),
+	}
+
+	for name, keyProvider := range keyProviders {
+		t.Run(name,
+			func(t *testing.T) {
+				configProc := conf.NewProcessor(
+					conf.ProcessorConfig{
+						KeyProvider: keyProvider,
+					},
+				)
+
+				tConfig, err := configProc.Load(layer)
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if !reflect.DeepEqual(tConfig, eConfig) {
+					t.Errorf("unexpected configuration returned: %#v is not equal to %#v",
+						tConfig, eConfig)
+				}
+			},
+		)
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	encValue, err := conf.Encrypt("stat_writer_pass", testKey)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	layer := conf.M{
+		"db": conf.M{
+			"password": encValue,
+		},
+	}
+
+	t.Run("no_key_provider",
+		func(t *testing.T) {
+			configProc := conf.NewProcessor(conf.ProcessorConfig{})
+			_, err := configProc.Load(layer)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "no key provider specified") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("wrong_key",
+		func(t *testing.T) {
+			configProc := conf.NewProcessor(
+				conf.ProcessorConfig{
+					KeyProvider: conf.KeyProviderFunc(
+						func() ([]byte, error) {
+							return otherKey, nil
+						},
+					),
+				},
+			)
+
+			_, err := configProc.Load(layer)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "can't decrypt encrypted value at node: db.password") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_key_size",
+		func(t *testing.T) {
+			_, err := conf.Encrypt("stat_writer_pass", []byte("short"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "encryption key must be 32 bytes long") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("unset_key_env",
+		func(t *testing.T) {
+			configProc := conf.NewProcessor(
+				conf.ProcessorConfig{
+					KeyProvider: conf.KeyEnv("TEST_CONF_UNSET_KEY"),
+				},
+			)
+
+			_, err := configProc.Load(layer)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "can't get encryption key") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
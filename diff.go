@@ -0,0 +1,177 @@
+package conf
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType type represents type of change of configuration parameter.
+type ChangeType int
+
+// Types of changes of configuration parameters.
+const (
+	Added ChangeType = iota + 1
+	Removed
+	Modified
+)
+
+// Change type represents a change of configuration parameter between two
+// configuration trees. Path is a path to the changed parameter in the same
+// syntax as in references. Old and New are previous and current values of the
+// parameter. Secret values in Old and New are redacted.
+type Change struct {
+	Type ChangeType
+	Path string
+	Old  any
+	New  any
+}
+
+// Diff function compares two configuration trees and returns the list of
+// changes of configuration parameters sorted by path. Sections are compared
+// recursively, arrays are compared element by element.
+func Diff(old, new M) []Change {
+	var changes []Change
+	diffNode("", old, new, &changes)
+
+	return changes
+}
+
+// FilterChanges function returns the changes of configuration parameters under
+// the specified path. Changes of parent sections of the path are also returned,
+// because they affect all nested parameters.
+func FilterChanges(changes []Change, prefix string) []Change {
+	var filtered []Change
+
+	for _, change := range changes {
+		if isSubpath(change.Path, prefix) || isSubpath(prefix, change.Path) {
+			filtered = append(filtered, change)
+		}
+	}
+
+	return filtered
+}
+
+// String method returns the name of change type.
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	}
+
+	return "unknown"
+}
+
+func diffNode(path string, old, new any, changes *[]Change) {
+	switch o := old.(type) {
+	case M:
+		if n, ok := new.(M); ok {
+			diffMap(path, o, n, changes)
+			return
+		}
+	case A:
+		if n, ok := new.(A); ok {
+			diffSlice(path, o, n, changes)
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		*changes = append(*changes,
+			Change{
+				Type: Modified,
+				Path: path,
+				Old:  Redact(old),
+				New:  Redact(new),
+			},
+		)
+	}
+}
+
+func diffMap(path string, old, new M, changes *[]Change) {
+	keys := make([]string, 0, len(old)+len(new))
+
+	for key := range old {
+		keys = append(keys, key)
+	}
+
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		oldValue, inOld := old[key]
+		newValue, inNew := new[key]
+		diffValue(joinPath(path, key), oldValue, inOld, newValue, inNew, changes)
+	}
+}
+
+func diffSlice(path string, old, new A, changes *[]Change) {
+	sliceLen := len(old)
+
+	if len(new) > sliceLen {
+		sliceLen = len(new)
+	}
+
+	for i := 0; i < sliceLen; i++ {
+		var oldValue, newValue any
+		inOld, inNew := i < len(old), i < len(new)
+
+		if inOld {
+			oldValue = old[i]
+		}
+
+		if inNew {
+			newValue = new[i]
+		}
+
+		diffValue(joinPath(path, strconv.Itoa(i)), oldValue, inOld, newValue, inNew,
+			changes)
+	}
+}
+
+func diffValue(path string, old any, inOld bool, new any, inNew bool,
+	changes *[]Change) {
+
+	if !inNew {
+		*changes = append(*changes,
+			Change{
+				Type: Removed,
+				Path: path,
+				Old:  Redact(old),
+			},
+		)
+	} else if !inOld {
+		*changes = append(*changes,
+			Change{
+				Type: Added,
+				Path: path,
+				New:  Redact(new),
+			},
+		)
+	} else {
+		diffNode(path, old, new, changes)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + refNameSep + key
+}
+
+func isSubpath(path, prefix string) bool {
+	return prefix == "" || path == prefix ||
+		strings.HasPrefix(path, prefix+refNameSep)
+}
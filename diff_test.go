@@ -0,0 +1,117 @@
+package conf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+func TestDiff(t *testing.T) {
+	oldConfig := conf.M{
+		"db": conf.M{
+			"connectors": conf.M{
+				"stat": conf.M{
+					"host":     "stat-master.mydb.com",
+					"port":     5432,
+					"password": conf.Secret("old_pass"),
+				},
+			},
+			"hosts": conf.A{"stat-slave1.mydb.com", "stat-slave2.mydb.com"},
+		},
+		"log": conf.M{
+			"level": "info",
+		},
+		"myapp": "foo",
+	}
+
+	newConfig := conf.M{
+		"db": conf.M{
+			"connectors": conf.M{
+				"stat": conf.M{
+					"host":     "stat-master.mydb.com",
+					"port":     5433,
+					"password": conf.Secret("new_pass"),
+				},
+			},
+			"hosts": conf.A{"stat-slave1.mydb.com"},
+		},
+		"log": conf.M{
+			"level":  "debug",
+			"format": "json",
+		},
+	}
+
+	tChanges := conf.Diff(oldConfig, newConfig)
+
+	eChanges := []conf.Change{
+		{
+			Type: conf.Modified,
+			Path: "db.connectors.stat.password",
+			Old:  "***",
+			New:  "***",
+		},
+		{
+			Type: conf.Modified,
+			Path: "db.connectors.stat.port",
+			Old:  5432,
+			New:  5433,
+		},
+		{
+			Type: conf.Removed,
+			Path: "db.hosts.1",
+			Old:  "stat-slave2.mydb.com",
+		},
+		{
+			Type: conf.Added,
+			Path: "log.format",
+			New:  "json",
+		},
+		{
+			Type: conf.Modified,
+			Path: "log.level",
+			Old:  "info",
+			New:  "debug",
+		},
+		{
+			Type: conf.Removed,
+			Path: "myapp",
+			Old:  "foo",
+		},
+	}
+
+	if !reflect.DeepEqual(tChanges, eChanges) {
+		t.Errorf("unexpected changes returned: %+v is not equal to %+v",
+			tChanges, eChanges)
+	}
+
+	t.Run("filter",
+		func(t *testing.T) {
+			tFiltered := conf.FilterChanges(tChanges, "db.connectors")
+			eFiltered := eChanges[:2]
+
+			if !reflect.DeepEqual(tFiltered, eFiltered) {
+				t.Errorf("unexpected changes returned: %+v is not equal to %+v",
+					tFiltered, eFiltered)
+			}
+
+			tFiltered = conf.FilterChanges(tChanges, "myapp.rootDir")
+			eFiltered = eChanges[5:]
+
+			if !reflect.DeepEqual(tFiltered, eFiltered) {
+				t.Errorf("unexpected changes returned: %+v is not equal to %+v",
+					tFiltered, eFiltered)
+			}
+		},
+	)
+
+	t.Run("change_type",
+		func(t *testing.T) {
+			if conf.Added.String() != "added" || conf.Removed.String() != "removed" ||
+				conf.Modified.String() != "modified" {
+
+				t.Error("unexpected names of change types")
+			}
+		},
+	)
+}
@@ -6,12 +6,38 @@
 Module conf is an extensible solution for cascading configuration. Module conf
 provides the configuration processor, that can load configuration layers from
 different sources and merges them into the one configuration tree. Module conf
-comes with built-in configuration loaders fileconf and envconf, and can be
-extended by third-party configuration loaders. Module conf do not watch for
-configuration changes, but you can implement this feature in the custom
-configuration loader. Configuration processor in conf module supports processing
-directives $include, $ref, $underlay and $overlay. See more information about
-directives below.
+comes with built-in configuration loaders fileconf, envconf, secretconf and
+flagconf, and can be extended by third-party configuration loaders. Module conf
+do not watch for configuration changes, but you can implement this feature in
+the custom configuration loader. Configuration processor in conf module supports processing
+directives $include, $ref, $underlay, $overlay and $secret. See more information
+about directives below.
+
+Configuration locators have the form "loader:value", where the value is passed to
+the configuration loader with the specified name. The loader name can be followed
+by the "?" or "!" modifier. The "?" modifier marks the locator as optional and
+suppresses errors about missing configuration sources. The "!" modifier marks the
+locator as required, so the locator must produce at least one configuration
+layer. Without modifiers the locator, that produces no configuration layers, is
+skipped. Modifiers can be used in $include directives too.
+
+	configRaw, err := configProc.Load(
+		"file!:myapp.yml",
+		"file?:local.yml",
+	)
+
+Configuration layers loaded by the locator can be mounted at the specified path
+in the configuration tree by adding the path and the "=" symbol before the loader
+name. A subtree of loaded configuration layers can be extracted by adding the "#"
+symbol and the path to the subtree after the locator value. Layers, that do not
+contain the subtree, are skipped. Paths have the same syntax as references.
+If the locator value without the path loads no layers, the "#" symbol is treated
+as a part of the locator value, so file names like "a#1.yml" can be loaded as is.
+
+	configRaw, err := configProc.Load(
+		"db.connectors=file:connectors.yml",
+		"log=file:big.yml#services.myapp.log",
+	)
 
 Configuration processor can include additional configuration sections to main
 configuration tree from external sources using $include directive. $include
@@ -20,6 +46,15 @@ $include directive accepts a list of configuration locators as a value.
 
 	db: { $include: [ "file:connectors.yml" ] }
 
+Locators in $include directives, that start with "./" or "../", are resolved
+relative to the origin of the including configuration layer, like the directory
+of the configuration file, if the layer was loaded by the same loader. So nested
+configuration trees can be moved as a unit. Loaders attach origins to
+configuration layers by returning values of Layer type and resolve relative
+locators by implementing RelativeLoader interface.
+
+	db: { $include: [ "file:./db/connectors.yml" ] }
+
 Configuration processor can expand references, that can be specified in string
 values, to configuration parameters within the same configuration tree (if you need
 references to configuration sections, see $ref directive).
@@ -134,5 +169,64 @@ the result on the configuration section where $overlay directive was specified.
 		test:
 			host: "localhost"
 			port: "54322"
+
+Configuration processor can mark sensitive values, like passwords, using $secret
+directive. $secret directive accepts a value or a configuration section as a
+value. Strings and scalars become values of Secret type, that are printed as "***"
+and are redacted by encoders. References in string values to secret values and
+$ref directives pointing to secret values also produce secret values. Secret
+values are never expanded.
+
+	db:
+		connectors:
+			stat:
+				host: "stat-master.mydb.com"
+				username: "stat_writer"
+				password: { $secret: "stat_writer_pass" }
+				dsn: "postgres://${db.connectors.stat.username}:${db.connectors.stat.password}@${db.connectors.stat.host}"
+
+Configuration processor can decrypt encrypted string values, so configuration
+files with credentials can be stored without plaintext values. Encrypted values
+are produced by Encrypt function and decrypted with the key, that is returned by
+KeyProvider specified in ProcessorConfig. Decrypted values are of Secret type.
+
+	db:
+		connectors:
+			stat:
+				password: "ENC[AES256_GCM,data:...,iv:...,tag:...,type:str]"
+
+Loaded configuration tree can be wrapped into Config type, that provides typed
+access to configuration parameters by path. Paths have the same syntax as
+references. Config methods return NotFoundError for missing parameters and
+TypeError for parameters, that can not be converted to the requested type.
+
+	config := conf.NewConfig(configRaw)
+	port, err := config.Int("db.connectors.stat.port")
+	mediaDir, err := config.String("myapp.mediaDirs.0")
+
+Services, that reload configuration, can keep decoded configuration structure in
+Value type. Value replaces the structure atomically only after successful
+decoding and validation, so readers never see partially decoded configuration.
+
+	var dbConfig conf.Value[DBConfig]
+	err := dbConfig.Reload(configProc, "file:db.json")
+	port := dbConfig.Load().Port
+
+Diff function compares two configuration trees and returns the list of changes
+with paths to changed parameters, that can be used to decide which subsystems
+must be reconfigured after reload. Secret values in changes are redacted.
+
+	for _, change := range conf.FilterChanges(conf.Diff(oldRaw, newRaw), "db") {
+		log.Printf("%s %s: %v -> %v", change.Path, change.Type, change.Old, change.New)
+	}
+
+Configuration tree can be written back in YAML, JSON or TOML format using Encode
+function or Encoder. Keys of maps are written in sorted order, so the output is
+stable. Configuration tree loaded with DisableProcessing option keeps directives
+and references unprocessed, so it can be encoded and loaded again without
+changes. Secret values are written as "***" unless RevealSecrets option of
+Encoder is set.
+
+	err := conf.Encode(os.Stdout, configRaw, "yaml")
 */
 package conf
@@ -0,0 +1,133 @@
+package conf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Encoder writes configuration trees to an output stream in YAML, JSON or TOML
+// format. Keys of maps are always written in sorted order, so the output for
+// the same configuration tree is stable.
+type Encoder struct {
+	w      io.Writer
+	config EncoderConfig
+}
+
+// EncoderConfig is a structure with configuration parameters for configuration
+// encoder.
+type EncoderConfig struct {
+	// Format specifies output format. Supported formats are "yaml" (or "yml"),
+	// "json" and "toml".
+	Format string
+
+	// Escape escapes references in string values by adding one more "$" symbol,
+	// so the processed configuration tree can be loaded again with enabled
+	// processing and produce the same result. Configuration trees loaded with
+	// DisableProcessing option contain unprocessed directives and references,
+	// that are written as is and must not be escaped. Revealed secret values
+	// are written as $secret directives.
+	Escape bool
+
+	// RevealSecrets enables writing of actual values of secrets. By default
+	// secret values are written as "***".
+	RevealSecrets bool
+}
+
+// NewEncoder method creates new encoder instance, that writes to w.
+func NewEncoder(w io.Writer, config EncoderConfig) *Encoder {
+	return &Encoder{
+		w:      w,
+		config: config,
+	}
+}
+
+// Encode method is a shortcut for encoding of configuration tree with default
+// encoder parameters. Directives and references in the configuration tree are
+// written as is, so the configuration tree loaded with DisableProcessing option
+// can be encoded and loaded again without changes.
+func Encode(w io.Writer, tree any, format string) error {
+	return NewEncoder(w, EncoderConfig{Format: format}).Encode(tree)
+}
+
+// Encode method writes configuration tree to the output stream.
+func (e *Encoder) Encode(tree any) error {
+	tree = e.conform(tree)
+
+	switch strings.ToLower(e.config.Format) {
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(e.w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(tree); err != nil {
+			return fmt.Errorf("%s: %s", errPref, err)
+		}
+
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("%s: %s", errPref, err)
+		}
+	case "json":
+		enc := json.NewEncoder(e.w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		if err := enc.Encode(tree); err != nil {
+			return fmt.Errorf("%s: %s", errPref, err)
+		}
+	case "toml":
+		if _, ok := tree.(M); !ok {
+			return fmt.Errorf("%s: configuration encoded to TOML must be of type "+
+				"\"map[string]any\", but got \"%T\"", errPref, tree)
+		}
+
+		if err := toml.NewEncoder(e.w).Encode(tree); err != nil {
+			return fmt.Errorf("%s: %s", errPref, err)
+		}
+	default:
+		return fmt.Errorf("%s: unknown encoding format: %s", errPref,
+			e.config.Format)
+	}
+
+	return nil
+}
+
+func (e *Encoder) conform(node any) any {
+	switch n := node.(type) {
+	case M:
+		m := make(M, len(n))
+
+		for key, value := range n {
+			m[key] = e.conform(value)
+		}
+
+		return m
+	case A:
+		s := make(A, len(n))
+
+		for i, value := range n {
+			s[i] = e.conform(value)
+		}
+
+		return s
+	case string:
+		if e.config.Escape {
+			return strings.ReplaceAll(n, "${", "$${")
+		}
+	case Secret:
+		if !e.config.RevealSecrets {
+			return redacted
+		}
+
+		if e.config.Escape {
+			return M{secretKey.String(): e.conform(string(n))}
+		}
+
+		return string(n)
+	}
+
+	return node
+}
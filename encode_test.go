@@ -0,0 +1,170 @@
+package conf_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+func TestEncode(t *testing.T) {
+	config := conf.M{
+		"paramB": "valB",
+		"paramA": conf.M{
+			"paramAB": 2,
+			"paramAA": true,
+		},
+		"paramC": conf.A{"valCA", "valCB"},
+	}
+
+	tests := map[string]string{
+		"yaml": "paramA:\n  paramAA: true\n  paramAB: 2\nparamB: valB\n" +
+			"paramC:\n  - valCA\n  - valCB\n",
+		"json": "{\n  \"paramA\": {\n    \"paramAA\": true,\n    \"paramAB\": 2\n  },\n" +
+			"  \"paramB\": \"valB\",\n  \"paramC\": [\n    \"valCA\",\n    \"valCB\"\n  ]\n}\n",
+		"toml": "paramB = \"valB\"\nparamC = [\"valCA\", \"valCB\"]\n\n" +
+			"[paramA]\n  paramAA = true\n  paramAB = 2\n",
+	}
+
+	for format, eOutput := range tests {
+		t.Run(format,
+			func(t *testing.T) {
+				var buf bytes.Buffer
+				err := conf.Encode(&buf, config, format)
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if buf.String() != eOutput {
+					t.Errorf("unexpected output: %q is not equal to %q", buf.String(),
+						eOutput)
+				}
+			},
+		)
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	layer := conf.M{
+		"paramA": "valA",
+		"paramB": "foo:${paramA}",
+		"paramC": "foo:$${paramA}",
+		"paramD": conf.M{"$ref": "paramA"},
+	}
+
+	t.Run("unprocessed",
+		func(t *testing.T) {
+			configProc := conf.NewProcessor(
+				conf.ProcessorConfig{
+					DisableProcessing: true,
+				},
+			)
+
+			config, err := configProc.Load(layer)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var buf bytes.Buffer
+			err = conf.Encode(&buf, config, "json")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var tConfig conf.M
+			err = json.Unmarshal(buf.Bytes(), &tConfig)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if !reflect.DeepEqual(tConfig, layer) {
+				t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+					tConfig, layer)
+			}
+		},
+	)
+
+	t.Run("escaped",
+		func(t *testing.T) {
+			configProc := conf.NewProcessor(conf.ProcessorConfig{})
+			eConfig, err := configProc.Load(layer)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var buf bytes.Buffer
+			enc := conf.NewEncoder(&buf,
+				conf.EncoderConfig{
+					Format: "json",
+					Escape: true,
+				},
+			)
+
+			err = enc.Encode(eConfig)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			var encLayer conf.M
+			err = json.Unmarshal(buf.Bytes(), &encLayer)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			tConfig, err := configProc.Load(encLayer)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if !reflect.DeepEqual(tConfig, eConfig) {
+				t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+					tConfig, eConfig)
+			}
+		},
+	)
+}
+
+func TestEncodeErrors(t *testing.T) {
+	t.Run("unknown_format",
+		func(t *testing.T) {
+			err := conf.Encode(&bytes.Buffer{}, conf.M{}, "xml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "unknown encoding format") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_toml_config",
+		func(t *testing.T) {
+			err := conf.Encode(&bytes.Buffer{}, conf.A{"valA"}, "toml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "configuration encoded to TOML must be") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
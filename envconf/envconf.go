@@ -9,26 +9,175 @@ loader are regular expressions. Here some examples:
 
 	env:^MYAPP_
 	env:.*
+
+By default environment variables of the current process are loaded. Another
+source of variables can be specified by NewLoaderFrom function, like the slice
+of "KEY=VALUE" pairs or the map. This allows to load environments captured from
+other processes and to test configuration without changes of the environment of
+the current process:
+
+	data, err := os.ReadFile("/proc/1234/environ")
+	envLdr := envconf.NewLoaderFrom(envconf.FromSlice(envconf.ParseEnviron(data)))
+
+By default names of environment variables become top-level parameter names as
+is. To override nested parameters, the loader can be configured to strip the
+prefix from names of variables, to split names into nested parameter names by
+the separator, to fold the case of parameter names and to treat numeric names
+as indices of arrays. For example, with prefix "MYAPP__", separator "__" and
+lower case, the variable MYAPP__DB__CONNECTORS__MAIN__HOST overrides the
+parameter db.connectors.main.host:
+
+	envLdr := envconf.NewLoaderWithConfig(
+		envconf.LoaderConfig{
+			Prefix:    "MYAPP__",
+			Separator: "__",
+			Case:      envconf.CaseLower,
+		},
+	)
+
+By default values of environment variables are strings. The loader can be
+configured to convert values to integers, floats, booleans and nil, to split
+values of specified parameters to lists and to parse values, that start with
+"[" or "{" symbols, as JSON or YAML flow literals:
+
+	MYAPP__DB__PORT=5432
+	MYAPP__HOSTS=a.example.com,b.example.com
+	MYAPP__OPTIONS='{"timeout": 10, "retries": 3}'
+
+Secrets are often passed to containers as files, and environment variables
+contain pathes of these files, like DB_PASSWORD_FILE=/run/secrets/db. With
+FileSuffix option the loader reads such files and stores their contents with
+trimmed trailing newlines under the name of the variable without the suffix.
+Loaded values are of conf.Secret type.
 */
 package envconf
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/iph0/conf/v2"
+	"github.com/iph0/conf/v2/internal/params"
+	yaml "gopkg.in/yaml.v3"
 )
 
-const errPref = "envconf"
+const (
+	errPref        = "envconf"
+	wordSep        = "_"
+	valueSep       = "="
+	keySep         = "."
+	defaultListSep = ","
+)
+
+// Case type represents case folding of parameter names.
+type Case int
+
+// Case folding modes of parameter names.
+const (
+	// CaseKeep keeps parameter names as is.
+	CaseKeep Case = iota
+
+	// CaseLower converts parameter names to lower case.
+	CaseLower
+
+	// CaseCamel converts parameter names to camel case. Words are separated by
+	// "_" symbol, for example, MAX_OPEN_CONNS becomes maxOpenConns.
+	CaseCamel
+)
 
 // Loader loads configuration layers from environment variables.
-type Loader struct{}
+type Loader struct {
+	config LoaderConfig
+}
+
+// LoaderConfig is a structure with configuration parameters for the loader.
+type LoaderConfig struct {
+	// Environ specifies a source of environment variables in the form
+	// "KEY=VALUE". If not specified, os.Environ is used.
+	Environ func() []string
+
+	// Prefix specifies a prefix of names of environment variables. Variables
+	// without the prefix are skipped, the prefix is stripped from parameter
+	// names. If not specified, all variables matched by the locator are loaded.
+	Prefix string
+
+	// Separator specifies a separator of nested parameter names in names of
+	// environment variables, like "__". If not specified, names of variables
+	// become top-level parameter names.
+	Separator string
+
+	// Case specifies case folding of parameter names.
+	Case Case
+
+	// Indices enables conversion of sections with numeric parameter names to
+	// arrays. For example, the variables HOSTS__0 and HOSTS__1 become the array
+	// hosts with two elements. Indices must go from zero without gaps. Note
+	// that arrays are not merged, so the array from environment variables
+	// replaces the array from other layers.
+	Indices bool
+
+	// InferTypes enables conversion of values to integers, floats, booleans and
+	// nil. Values "true" and "false" become booleans, value "null" becomes nil.
+	// Numbers are converted only if the conversion is lossless, so values like
+	// "0755" or "1.10" remain strings. Other values, that can not be converted,
+	// remain strings.
+	InferTypes bool
 
-// NewLoader method creates new loader instance.
+	// ListKeys specifies full names of parameters, like "db.hosts", which values
+	// are split to lists by ListSeparator. Names are compared after stripping of
+	// the prefix and case folding.
+	ListKeys []string
+
+	// ListSeparator specifies a separator of list elements in values of
+	// parameters specified in ListKeys. Elements are trimmed. If not specified,
+	// "," is used.
+	ListSeparator string
+
+	// ParseLiterals enables parsing of values, that start with "[" or "{"
+	// symbols, as JSON or YAML flow literals, like ["a", "b"] or {a: 1, b: 2}.
+	ParseLiterals bool
+
+	// FileSuffix specifies a suffix of names of environment variables, which
+	// values are pathes of files with values of parameters, like "_FILE". The
+	// file is read, trailing newlines are stripped and the contents is stored
+	// as conf.Secret value under the name of variable without the suffix. It is
+	// an error to set both variables with and without the suffix. If not
+	// specified, such variables are loaded as usual.
+	FileSuffix string
+}
+
+// NewLoader method creates new loader instance with default configuration
+// parameters.
 func NewLoader() *Loader {
-	return &Loader{}
+	return NewLoaderWithConfig(LoaderConfig{})
+}
+
+// NewLoaderFrom method creates new loader instance, that loads environment
+// variables from the specified source instead of the environment of the current
+// process.
+func NewLoaderFrom(environ func() []string) *Loader {
+	return NewLoaderWithConfig(LoaderConfig{Environ: environ})
+}
+
+// NewLoaderWithConfig method creates new loader instance with specified
+// configuration parameters.
+func NewLoaderWithConfig(config LoaderConfig) *Loader {
+	if config.Environ == nil {
+		config.Environ = os.Environ
+	}
+
+	if config.ListSeparator == "" {
+		config.ListSeparator = defaultListSep
+	}
+
+	return &Loader{
+		config: config,
+	}
 }
 
 // Load method loads configuration layer from environment variables.
@@ -39,16 +188,320 @@ func (l *Loader) Load(re string) ([]any, error) {
 		return nil, fmt.Errorf("%s: %s", errPref, err)
 	}
 
-	envs := os.Environ()
+	envs := make(map[string]string)
+	var names []string
+
+	for _, envStr := range l.config.Environ() {
+		tokens := strings.SplitN(envStr, valueSep, 2)
+
+		if len(tokens) < 2 || !reObj.MatchString(tokens[0]) {
+			continue
+		}
+
+		if _, ok := envs[tokens[0]]; !ok {
+			names = append(names, tokens[0])
+		}
+
+		envs[tokens[0]] = tokens[1]
+	}
+
+	sort.Strings(names)
 	layer := make(conf.M)
 
-	for _, envStr := range envs {
-		tokens := strings.SplitN(envStr, "=", 2)
+	for _, name := range names {
+		var value any
+		baseName := name
+		suffix := l.config.FileSuffix
+
+		if suffix != "" && strings.HasSuffix(name, suffix) {
+			baseName = strings.TrimSuffix(name, suffix)
+
+			if _, ok := envs[baseName]; ok {
+				return nil, fmt.Errorf("%s: both variables %s and %s are set",
+					errPref, baseName, name)
+			}
+		}
+
+		keys, ok := l.keys(baseName)
+
+		if !ok {
+			continue
+		}
+
+		if baseName != name {
+			value, err = readSecret(envs[name])
+
+			if err != nil {
+				return nil, fmt.Errorf("%s: can't read file %s specified by "+
+					"variable %s: %s", errPref, envs[name], name, err)
+			}
+		} else {
+			value, err = l.value(keys, envs[name])
 
-		if reObj.MatchString(tokens[0]) {
-			layer[tokens[0]] = tokens[1]
+			if err != nil {
+				return nil, fmt.Errorf("%s: can't parse value of variable %s: %s",
+					errPref, name, err)
+			}
 		}
+
+		err = params.Set(layer, keys, value, true)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %s", errPref, err, name)
+		}
+	}
+
+	if l.config.Indices {
+		node, err := toArrays(layer, nil)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", errPref, err)
+		}
+
+		return []any{node}, nil
 	}
 
 	return []any{layer}, nil
 }
+
+// keys method converts the name of environment variable to the list of
+// parameter names.
+func (l *Loader) keys(name string) ([]string, bool) {
+	if l.config.Prefix != "" {
+		if !strings.HasPrefix(name, l.config.Prefix) {
+			return nil, false
+		}
+
+		name = strings.TrimPrefix(name, l.config.Prefix)
+	}
+
+	if name == "" {
+		return nil, false
+	}
+
+	var keys []string
+
+	if l.config.Separator != "" {
+		keys = strings.Split(name, l.config.Separator)
+	} else {
+		keys = []string{name}
+	}
+
+	for i, key := range keys {
+		if key == "" {
+			return nil, false
+		}
+
+		keys[i] = foldCase(key, l.config.Case)
+	}
+
+	return keys, true
+}
+
+// value method converts the value of environment variable according to the
+// configuration of the loader.
+func (l *Loader) value(keys []string, str string) (any, error) {
+	if l.config.ParseLiterals {
+		trimmed := strings.TrimSpace(str)
+
+		if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+			var value any
+			err := yaml.Unmarshal([]byte(trimmed), &value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return conform(value), nil
+		}
+	}
+
+	if len(l.config.ListKeys) > 0 {
+		name := strings.Join(keys, keySep)
+
+		for _, listKey := range l.config.ListKeys {
+			if listKey != name {
+				continue
+			}
+
+			elems := strings.Split(str, l.config.ListSeparator)
+			list := make(conf.A, len(elems))
+
+			for i, elem := range elems {
+				list[i] = l.scalar(strings.TrimSpace(elem))
+			}
+
+			return list, nil
+		}
+	}
+
+	return l.scalar(str), nil
+}
+
+func (l *Loader) scalar(str string) any {
+	if !l.config.InferTypes {
+		return str
+	}
+
+	return params.Scalar(str)
+}
+
+// conform converts maps with keys of any type, that can be produced by YAML
+// parser, to configuration sections.
+func conform(node any) any {
+	switch n := node.(type) {
+	case map[string]any:
+		for key, value := range n {
+			n[key] = conform(value)
+		}
+	case map[any]any:
+		m := make(conf.M, len(n))
+
+		for key, value := range n {
+			m[fmt.Sprintf("%v", key)] = conform(value)
+		}
+
+		return m
+	case []any:
+		for i, value := range n {
+			n[i] = conform(value)
+		}
+	}
+
+	return node
+}
+
+func foldCase(key string, c Case) string {
+	switch c {
+	case CaseLower:
+		return strings.ToLower(key)
+	case CaseCamel:
+		words := strings.Split(strings.ToLower(key), wordSep)
+		var b strings.Builder
+
+		for _, word := range words {
+			if word == "" {
+				continue
+			}
+
+			if b.Len() > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+
+			b.WriteString(word)
+		}
+
+		return b.String()
+	}
+
+	return key
+}
+
+// toArrays converts sections, that have only numeric parameter names, to
+// arrays. Indices must go from zero without gaps, so the size of the array is
+// limited by the number of variables.
+func toArrays(node any, keys []string) (any, error) {
+	m, ok := node.(conf.M)
+
+	if !ok {
+		return node, nil
+	}
+
+	isArray := len(m) > 0
+	maxIdx := -1
+
+	for key, value := range m {
+		value, err := toArrays(value, append(keys[:len(keys):len(keys)], key))
+
+		if err != nil {
+			return nil, err
+		}
+
+		m[key] = value
+
+		if !isArray {
+			continue
+		}
+
+		idx, err := strconv.Atoi(key)
+
+		if err != nil || idx < 0 || strconv.Itoa(idx) != key {
+			isArray = false
+			continue
+		}
+
+		if idx > maxIdx {
+			maxIdx = idx
+		}
+	}
+
+	if !isArray {
+		return m, nil
+	}
+
+	if maxIdx >= len(m) {
+		return nil, fmt.Errorf("indices of array %s must go from 0 without gaps, "+
+			"but got index %d for %d elements", strings.Join(keys, keySep), maxIdx,
+			len(m))
+	}
+
+	arr := make(conf.A, len(m))
+
+	for key, value := range m {
+		idx, _ := strconv.Atoi(key)
+		arr[idx] = value
+	}
+
+	return arr, nil
+}
+
+func readSecret(path string) (conf.Secret, error) {
+	if path == "" {
+		return "", errors.New("empty path")
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return "", err
+	}
+
+	return conf.Secret(strings.TrimRight(string(data), "\r\n")), nil
+}
+
+// FromSlice method returns a source of environment variables, that returns
+// variables from the slice of "KEY=VALUE" pairs.
+func FromSlice(envs []string) func() []string {
+	return func() []string {
+		return envs
+	}
+}
+
+// FromMap method returns a source of environment variables, that returns
+// variables from the map.
+func FromMap(m map[string]string) func() []string {
+	return func() []string {
+		envs := make([]string, 0, len(m))
+
+		for name, value := range m {
+			envs = append(envs, name+valueSep+value)
+		}
+
+		return envs
+	}
+}
+
+// ParseEnviron method splits the environment in the format of
+// /proc/<pid>/environ file, where "KEY=VALUE" pairs are separated by NUL
+// symbols, to the slice of pairs.
+func ParseEnviron(data []byte) []string {
+	var envs []string
+
+	for _, env := range strings.Split(string(data), "\x00") {
+		if env != "" {
+			envs = append(envs, env)
+		}
+	}
+
+	return envs
+}
@@ -2,6 +2,7 @@ package envconf
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -9,6 +10,34 @@ import (
 	"github.com/iph0/conf/v2"
 )
 
+var (
+	nestedEnv = map[string]string{
+		"NESTED__DB__CONNECTORS__MAIN__HOST": "localhost",
+		"NESTED__DB__MAX_OPEN_CONNS":         "10",
+		"NESTED__HOSTS__0":                   "a.example.com",
+		"NESTED__HOSTS__1":                   "b.example.com",
+		"NESTED__PORTS__0":                   "80",
+		"NESTED__PORTS__1":                   "8080",
+		"NESTED__CONFLICT":                   "foo",
+		"NESTED__CONFLICT__PARAM":            "bar",
+	}
+
+	typedEnv = map[string]string{
+		"TYPED__PORT":    "5432",
+		"TYPED__RATIO":   "0.5",
+		"TYPED__DEBUG":   "true",
+		"TYPED__NAME":    "stat",
+		"TYPED__VERSION": "1.2.3",
+		"TYPED__RELEASE": "1.10",
+		"TYPED__MODE":    "0755",
+		"TYPED__HOSTS":   "a.example.com, b.example.com",
+		"TYPED__PORTS":   "80,443",
+		"TYPED__OPTIONS": "{timeout: 10, retries: [1, 2]}",
+		"TYPED__TAGS":    `["x", "y"]`,
+		"TYPED__BROKEN":  "[1, 2",
+	}
+)
+
 func init() {
 	os.Setenv("TEST_FOO", "bar")
 	os.Setenv("TEST_MOO", "jar")
@@ -43,6 +72,323 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoadFrom(t *testing.T) {
+	t.Parallel()
+
+	environ := ParseEnviron([]byte("TEST_FOO=proc\x00TEST_BAR=a=b\x00OTHER=c\x00\x00"))
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"proc": NewLoaderFrom(FromSlice(environ)),
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load("proc:^TEST_")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"TEST_FOO": "proc",
+		"TEST_BAR": "a=b",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v", tConfig)
+	}
+
+	t.Run("duplicates",
+		func(t *testing.T) {
+			tLayers, err := NewLoaderFrom(FromSlice([]string{"A=1", "A=2"})).Load("^A$")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.M{"A": "2"},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %#v", tLayers)
+			}
+		},
+	)
+}
+
+func TestLoadNested(t *testing.T) {
+	t.Parallel()
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"map": &mapLoader{
+					m: conf.M{
+						"default": conf.M{
+							"db": conf.M{
+								"connectors": conf.M{
+									"main": conf.M{
+										"host": "db.example.com",
+										"port": 5432,
+									},
+								},
+
+								"maxOpenConns": 5,
+							},
+						},
+					},
+				},
+
+				"env": NewLoaderWithConfig(
+					LoaderConfig{
+						Environ:   FromMap(nestedEnv),
+						Prefix:    "NESTED__",
+						Separator: "__",
+						Case:      CaseCamel,
+						Indices:   true,
+					},
+				),
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load(
+		"map:default",
+		"env:^NESTED__(DB|HOSTS|PORTS)__",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"db": conf.M{
+			"connectors": conf.M{
+				"main": conf.M{
+					"host": "localhost",
+					"port": 5432,
+				},
+			},
+
+			"maxOpenConns": "10",
+		},
+
+		"hosts": conf.A{"a.example.com", "b.example.com"},
+		"ports": conf.A{"80", "8080"},
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v", tConfig)
+	}
+
+	t.Run("lower_case",
+		func(t *testing.T) {
+			envLdr := NewLoaderWithConfig(
+				LoaderConfig{
+					Environ:   FromMap(nestedEnv),
+					Prefix:    "NESTED__",
+					Separator: "__",
+					Case:      CaseLower,
+				},
+			)
+
+			tLayers, err := envLdr.Load("^NESTED__DB__")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.M{
+					"db": conf.M{
+						"connectors": conf.M{
+							"main": conf.M{"host": "localhost"},
+						},
+
+						"max_open_conns": "10",
+					},
+				},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %#v", tLayers)
+			}
+		},
+	)
+}
+
+func TestLoadTyped(t *testing.T) {
+	t.Parallel()
+
+	envLdr := NewLoaderWithConfig(
+		LoaderConfig{
+			Environ:       FromMap(typedEnv),
+			Prefix:        "TYPED__",
+			Separator:     "__",
+			Case:          CaseLower,
+			InferTypes:    true,
+			ListKeys:      []string{"hosts", "ports"},
+			ParseLiterals: true,
+		},
+	)
+
+	tLayers, err := envLdr.Load("^TYPED__(PORT|RATIO|DEBUG|NAME|VERSION|RELEASE|MODE|HOSTS|PORTS|OPTIONS|TAGS)$")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eLayers := []any{
+		conf.M{
+			"port":    5432,
+			"ratio":   0.5,
+			"debug":   true,
+			"name":    "stat",
+			"version": "1.2.3",
+			"release": "1.10",
+			"mode":    "0755",
+			"hosts":   conf.A{"a.example.com", "b.example.com"},
+			"ports":   conf.A{80, 443},
+
+			"options": conf.M{
+				"timeout": 10,
+				"retries": conf.A{1, 2},
+			},
+
+			"tags": conf.A{"x", "y"},
+		},
+	}
+
+	if !reflect.DeepEqual(tLayers, eLayers) {
+		t.Errorf("unexpected layers returned: %#v", tLayers)
+	}
+
+	t.Run("no_inference",
+		func(t *testing.T) {
+			tLayers, err := NewLoaderWithConfig(
+				LoaderConfig{
+					Environ:       FromMap(typedEnv),
+					Prefix:        "TYPED__",
+					ListKeys:      []string{"PORTS"},
+					ListSeparator: ",",
+				},
+			).Load("^TYPED__(PORT|PORTS|TAGS)$")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.M{
+					"PORT":  "5432",
+					"PORTS": conf.A{"80", "443"},
+					"TAGS":  `["x", "y"]`,
+				},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %#v", tLayers)
+			}
+		},
+	)
+}
+
+func TestLoadFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "db_password")
+	err := os.WriteFile(secretFile, []byte("s3cret\n"), 0600)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	newLoader := func(env map[string]string) *Loader {
+		return NewLoaderWithConfig(
+			LoaderConfig{
+				Environ:    FromMap(env),
+				Prefix:     "SECRET__",
+				Separator:  "__",
+				Case:       CaseLower,
+				FileSuffix: "_FILE",
+			},
+		)
+	}
+
+	tLayers, err := newLoader(
+		map[string]string{
+			"SECRET__DB__PASSWORD_FILE": secretFile,
+			"SECRET__DB__USER":          "admin",
+		},
+	).Load("^SECRET__")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eLayers := []any{
+		conf.M{
+			"db": conf.M{
+				"password": conf.Secret("s3cret"),
+				"user":     "admin",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(tLayers, eLayers) {
+		t.Errorf("unexpected layers returned: %#v", tLayers)
+	}
+
+	t.Run("both_variables_set",
+		func(t *testing.T) {
+			_, err := newLoader(
+				map[string]string{
+					"SECRET__DB__PASSWORD_FILE": secretFile,
+					"SECRET__DB__PASSWORD":      "pass",
+				},
+			).Load("^SECRET__")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "both variables SECRET__DB__PASSWORD and SECRET__DB__PASSWORD_FILE are set") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("missing_file",
+		func(t *testing.T) {
+			missingFile := filepath.Join(dir, "missing")
+
+			_, err := newLoader(
+				map[string]string{
+					"SECRET__DB__PASSWORD_FILE": missingFile,
+				},
+			).Load("^SECRET__")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "can't read file "+missingFile+
+				" specified by variable SECRET__DB__PASSWORD_FILE") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
 func TestErrors(t *testing.T) {
 	configProc := NewProcessor()
 
@@ -57,6 +403,71 @@ func TestErrors(t *testing.T) {
 			}
 		},
 	)
+
+	t.Run("invalid_literal",
+		func(t *testing.T) {
+			envLdr := NewLoaderWithConfig(
+				LoaderConfig{
+					Environ:       FromMap(typedEnv),
+					Prefix:        "TYPED__",
+					ParseLiterals: true,
+				},
+			)
+
+			_, err := envLdr.Load("^TYPED__BROKEN$")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "can't parse value of variable TYPED__BROKEN") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("sparse_indices",
+		func(t *testing.T) {
+			envLdr := NewLoaderWithConfig(
+				LoaderConfig{
+					Environ: FromMap(
+						map[string]string{
+							"A__X__0":       "1",
+							"A__X__2000000": "1",
+						},
+					),
+					Separator: "__",
+					Indices:   true,
+				},
+			)
+
+			_, err := envLdr.Load("^A__")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "indices of array A.X must go from 0 without gaps") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("conflicting_parameters",
+		func(t *testing.T) {
+			envLdr := NewLoaderWithConfig(
+				LoaderConfig{
+					Environ:   FromMap(nestedEnv),
+					Prefix:    "NESTED__",
+					Separator: "__",
+				},
+			)
+
+			_, err := envLdr.Load("^NESTED__CONFLICT")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "parameter CONFLICT.PARAM conflicts with value of parameter CONFLICT") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
 }
 
 func NewProcessor() *conf.Processor {
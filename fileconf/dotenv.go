@@ -0,0 +1,336 @@
+package fileconf
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iph0/conf/v2"
+)
+
+// unmarshalDotenv parses files in dotenv format. Each line contains an
+// assignment KEY=value, optionally prefixed by "export" keyword. Unquoted
+// values are trimmed and can be followed by a comment. Single-quoted values are
+// taken literally. Double-quoted values can span multiple lines and support
+// escape sequences. References to variables in the form ${VAR}, $VAR,
+// ${VAR:-default} and ${VAR-default} are expanded in unquoted and double-quoted
+// values using variables defined earlier in the file and environment variables.
+func unmarshalDotenv(data []byte) ([]any, error) {
+	p := &dotenvParser{
+		data:  []rune(strings.ReplaceAll(string(data), "\r\n", "\n")),
+		line:  1,
+		layer: make(conf.M),
+	}
+
+	err := p.parse()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{p.layer}, nil
+}
+
+type dotenvParser struct {
+	data  []rune
+	pos   int
+	line  int
+	layer conf.M
+}
+
+func (p *dotenvParser) parse() error {
+	for {
+		p.skipBlank()
+
+		if p.eof() {
+			return nil
+		}
+
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		key := p.readKey()
+
+		if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+			p.skipSpaces()
+			key = p.readKey()
+		}
+
+		if key == "" {
+			return p.errorf("invalid variable name")
+		}
+
+		p.skipSpaces()
+
+		if p.eof() || p.peek() != '=' {
+			return p.errorf("missing \"=\" after variable name %s", key)
+		}
+
+		p.pos++
+		p.skipSpaces()
+
+		value, err := p.readValue()
+
+		if err != nil {
+			return err
+		}
+
+		p.layer[key] = value
+	}
+}
+
+func (p *dotenvParser) readKey() string {
+	start := p.pos
+
+	for !p.eof() {
+		if r := p.peek(); isNameRune(r) || r == '.' || r == '-' {
+			p.pos++
+			continue
+		}
+
+		break
+	}
+
+	return string(p.data[start:p.pos])
+}
+
+func (p *dotenvParser) readValue() (string, error) {
+	if p.eof() {
+		return "", nil
+	}
+
+	var value string
+
+	switch p.peek() {
+	case '\'':
+		line := p.line
+		p.pos++
+		start := p.pos
+
+		for !p.eof() && p.peek() != '\'' {
+			p.next()
+		}
+
+		if p.eof() {
+			return "", p.errorfAt(line, "unterminated single-quoted value")
+		}
+
+		value = string(p.data[start:p.pos])
+		p.pos++
+	case '"':
+		line := p.line
+		p.pos++
+		start := p.pos
+
+		for !p.eof() && p.peek() != '"' {
+			if p.next() == '\\' && !p.eof() {
+				p.next()
+			}
+		}
+
+		if p.eof() {
+			return "", p.errorfAt(line, "unterminated double-quoted value")
+		}
+
+		value = p.expand(p.data[start:p.pos], true)
+		p.pos++
+	default:
+		start := p.pos
+
+		for !p.eof() && p.peek() != '\n' {
+			if p.peek() == '#' && p.pos > start &&
+				(p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+
+				break
+			}
+
+			p.pos++
+		}
+
+		raw := strings.TrimSpace(string(p.data[start:p.pos]))
+		value = p.expand([]rune(raw), false)
+	}
+
+	p.skipSpaces()
+
+	if !p.eof() && p.peek() == '#' {
+		p.skipLine()
+	} else if !p.eof() && p.peek() != '\n' {
+		return "", p.errorf("unexpected characters after value")
+	}
+
+	return value, nil
+}
+
+func (p *dotenvParser) expand(runes []rune, quoted bool) string {
+	runesLen := len(runes)
+	var b strings.Builder
+
+	for i := 0; i < runesLen; i++ {
+		r := runes[i]
+
+		if r == '\\' && i+1 < runesLen {
+			esc := runes[i+1]
+
+			if esc == '$' {
+				b.WriteRune(esc)
+				i++
+
+				continue
+			} else if quoted {
+				switch esc {
+				case 'n':
+					b.WriteRune('\n')
+				case 'r':
+					b.WriteRune('\r')
+				case 't':
+					b.WriteRune('\t')
+				case '"', '\\':
+					b.WriteRune(esc)
+				default:
+					b.WriteRune(r)
+					b.WriteRune(esc)
+				}
+
+				i++
+
+				continue
+			}
+		}
+
+		if r != '$' || i+1 >= runesLen {
+			b.WriteRune(r)
+			continue
+		}
+
+		if runes[i+1] == '{' {
+			j := i + 2
+
+			for depth := 1; j < runesLen; j++ {
+				if runes[j] == '{' {
+					depth++
+				} else if runes[j] == '}' {
+					depth--
+
+					if depth == 0 {
+						break
+					}
+				}
+			}
+
+			if j == runesLen {
+				b.WriteRune(r)
+				continue
+			}
+
+			b.WriteString(p.lookupExpr(string(runes[i+2 : j])))
+			i = j
+
+			continue
+		}
+
+		j := i + 1
+
+		for j < runesLen && isNameRune(runes[j]) {
+			j++
+		}
+
+		if j == i+1 {
+			b.WriteRune(r)
+			continue
+		}
+
+		b.WriteString(p.lookup(string(runes[i+1 : j])))
+		i = j - 1
+	}
+
+	return b.String()
+}
+
+func (p *dotenvParser) lookupExpr(expr string) string {
+	if i := strings.Index(expr, ":-"); i != -1 {
+		if value := p.lookup(expr[:i]); value != "" {
+			return value
+		}
+
+		return p.expand([]rune(expr[i+2:]), false)
+	}
+
+	if i := strings.Index(expr, "-"); i != -1 {
+		if value, ok := p.lookupVar(expr[:i]); ok {
+			return value
+		}
+
+		return p.expand([]rune(expr[i+1:]), false)
+	}
+
+	return p.lookup(expr)
+}
+
+func (p *dotenvParser) lookup(name string) string {
+	value, _ := p.lookupVar(name)
+	return value
+}
+
+func (p *dotenvParser) lookupVar(name string) (string, bool) {
+	if value, ok := p.layer[name]; ok {
+		return value.(string), true
+	}
+
+	return os.LookupEnv(name)
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *dotenvParser) peek() rune {
+	return p.data[p.pos]
+}
+
+func (p *dotenvParser) next() rune {
+	r := p.data[p.pos]
+	p.pos++
+
+	if r == '\n' {
+		p.line++
+	}
+
+	return r
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipBlank() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t' || p.peek() == '\n') {
+		p.next()
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return p.errorfAt(p.line, format, args...)
+}
+
+func (p *dotenvParser) errorfAt(line int, format string, args ...any) error {
+	return &ParseError{
+		Line: line,
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+		r >= '0' && r <= '9'
+}
@@ -0,0 +1,150 @@
+package fileconf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var linePrefixRe = regexp.MustCompile(`^(?:\w+: )?line (\d+)(?: \(last key .*?\))?: `)
+
+// ParseError is returned by the loader, if configuration file can not be
+// parsed. Line and column are counted from 1 and are zero, if the parser did not
+// report the position. Parsers can return ParseError with the position to let
+// the loader report it, the path is set by the loader.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+// Error method returns error message with the location in the form
+// path:line:column.
+func (e *ParseError) Error() string {
+	var loc string
+
+	if e.Path != "" {
+		loc = e.Path
+
+		if e.Line > 0 {
+			loc += ":" + strconv.Itoa(e.Line)
+
+			if e.Column > 0 {
+				loc += ":" + strconv.Itoa(e.Column)
+			}
+		}
+	} else if e.Line > 0 {
+		loc = "line " + strconv.Itoa(e.Line)
+
+		if e.Column > 0 {
+			loc += ", column " + strconv.Itoa(e.Column)
+		}
+	}
+
+	if loc == "" {
+		return fmt.Sprintf("%s: %s", errPref, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %s: %s", errPref, loc, e.Err)
+}
+
+// Unwrap method returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// lineError converts errors of parsers, that report the line in the message,
+// like "yaml: line 3: ...", to ParseError.
+func lineError(err error) error {
+	line, msg := trimLinePrefix(err.Error())
+
+	if line == 0 {
+		return err
+	}
+
+	return &ParseError{
+		Line: line,
+		Err:  errors.New(msg),
+	}
+}
+
+func trimLinePrefix(msg string) (int, string) {
+	match := linePrefixRe.FindStringSubmatch(msg)
+
+	if match == nil {
+		return 0, msg
+	}
+
+	line, _ := strconv.Atoi(match[1])
+
+	return line, msg[len(match[0]):]
+}
+
+// jsonError converts errors of encoding/json package, that report byte offsets,
+// to ParseError with the line and the column.
+func jsonError(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	} else {
+		return err
+	}
+
+	if offset > 0 {
+		offset--
+	}
+
+	return offsetError(data, int(offset), err)
+}
+
+func offsetError(data []byte, offset int, err error) error {
+	line, col := position(data, offset)
+
+	return &ParseError{
+		Line:   line,
+		Column: col,
+		Err:    err,
+	}
+}
+
+func yamlError(err error) error {
+	var typeErr *yaml.TypeError
+
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		return lineError(errors.New(typeErr.Errors[0]))
+	}
+
+	return lineError(err)
+}
+
+func tomlError(data []byte, err error) error {
+	var parseErr toml.ParseError
+
+	if !errors.As(err, &parseErr) {
+		return err
+	}
+
+	line, col := position(data, parseErr.Position.Start)
+	_, msg := trimLinePrefix(err.Error())
+
+	if line != parseErr.Position.Line {
+		col = 0
+	}
+
+	return &ParseError{
+		Line:   parseErr.Position.Line,
+		Column: col,
+		Err:    errors.New(msg),
+	}
+}
@@ -4,48 +4,254 @@
 
 /*
 Package fileconf is configuration loader for the conf package. It loads
-configuration layers from YAML, JSON or TOML files. Configuration locators for
-this loader are relative pathes or glob patterns. See standart package
-path/filepath for more information about syntax of glob patterns. Here some
-examples:
+configuration layers from YAML, JSON, TOML, dotenv (.env), INI or Java
+.properties files. Configuration locators for this loader are relative pathes or
+glob patterns. See standart package path/filepath for more information about
+syntax of glob patterns. Here some examples:
 
 	file:myapp/dirs.yml
 	file:myapp/servers.toml
 	file:myapp/*.json
 	file:myapp/*.*
+	file:myapp/conf.d/**
+
+The "**" element of the pattern matches zero or more directories, so the last
+example matches all files in the directory conf.d and in its subdirectories.
+The "**" element can be followed by other elements of the pattern to match
+only files with specific names. Directories matched by the pattern are skipped.
+
+Files matched by the pattern are loaded in natural order of their pathes: pathes
+are compared element by element, elements are compared lexically, but sequences
+of digits are compared numerically. For example, 10-base.yml is loaded before
+100-override.yml and files of the directory are loaded before files of its
+subdirectory with a greater name. This makes drop-in directories, like
+/etc/myapp/conf.d, work predictably. If several directories are specified, files
+from each directory are loaded in this order one directory after another.
+
+Patterns in $include directives, that start with "./" or "../", are resolved
+relative to the directory of the including configuration file:
+
+	file:./connectors.yml
+	file:../shared/*.yml
+
+The format of configuration files is determined by file extension. For files
+without extension or with unusual extension the format can be specified before
+the pattern:
+
+	file:yaml:myapp/config
+	file:env:.env.local
+
+The pattern "-" loads configuration from standard input or from the reader
+specified by Stdin option. If the format is not specified explicitly, the data
+is parsed as YAML, which also accepts JSON:
+
+	file:-
+	file:toml:-
+
+Configuration can also be loaded from any io.Reader, like in-memory data
+generated on the fly, using the loader created by NewReaderLoader function.
+Locators for this loader are names of formats, for example stdin:yaml. Parse and
+ParseReader functions parse data in the specified format without a loader.
+
+YAML files can contain values with custom tags:
+
+	password: !env DB_PASS
+	tls: !include tls.yml
+	cert: !file certs/server.pem
+	replica: !ref db.primary
+
+The !env tag takes the value of the environment variable at the time of parsing,
+if the variable is not set, the value is nil. The !include tag takes a pattern or
+a list of patterns, that are resolved relative to the directory of the including
+file, and merges configuration layers from matched files. The !file tag takes
+contents of the file, that is resolved relative to the directory of the
+including file. Absolute pathes in the !file tag are subject to Confine option
+and are not allowed in fs.FS. The !include and !file tags are not allowed in
+data, that is not loaded from files, like standard input or data parsed by
+Parse function. The !ref tag is converted to $ref directive. Additional tags can
+be registered by RegisterYAMLTag function.
+
+Dotenv files are loaded as flat configuration layers, where variable names
+become parameter names. Dotenv parser supports "export" prefixes, comments,
+single-quoted literal values, double-quoted values with escape sequences, that
+can span multiple lines, and expansion of variables in the form ${VAR}, $VAR,
+${VAR:-default} and ${VAR-default} using variables defined earlier in the file
+and environment variables.
+
+Files with .jsonc and .json5 extensions are parsed as JSON with comments,
+trailing commas, unquoted keys and single-quoted strings. The same parsing can be
+enabled for files with .json extension by LenientJSON option.
+
+Sections of INI files become nested configuration sections, dotted section names,
+like [db.connectors], become nested sections too. Dotted keys of .properties
+files, like db.host, become nested parameters. Duplicate keys in INI and
+.properties files are errors.
+
+Configuration files can be loaded from any file system implementing fs.FS
+interface, like embed.FS, using the loader created by NewFSLoader function. This
+allows to compile default configuration into the binary and to layer it under
+configuration files on disk:
+
+	//go:embed etc
+	var defaults embed.FS
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"defaults": fileconf.NewFSLoader(defaults, "etc"),
+				"file":     fileconf.NewLoader("/etc/myapp"),
+			},
+		},
+	)
+
+	configRaw, err := configProc.Load("defaults:myapp.yml", "file:myapp.yml")
+
+Parsers of additional formats can be registered globally by RegisterFormat
+function or for the specific loader in LoaderConfig.
+
+If configuration locators can come from sources with lower trust, like $include
+directives of other layers, the loader can be restricted by options in
+LoaderConfig. Confine option rejects patterns and files, that resolve outside of
+configuration directories, including through symbolic links. NoSymlinks option
+rejects configuration files, that are symbolic links. MaxFileSize and MaxPerm
+options limit the size and permissions of configuration files, for example, to
+refuse world-writable files.
+
+Errors of parsing of configuration files are returned as ParseError, that
+contains the absolute path of the file, the line and the column, if they are
+reported by the parser. The error message has the form path:line:column, that is
+understood by editors and other tools.
 */
 package fileconf
 
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/BurntSushi/toml"
 	"github.com/iph0/conf/v2"
 	yaml "gopkg.in/yaml.v3"
 )
 
-const errPref = "fileconf"
+const (
+	errPref   = "fileconf"
+	formatSep = ":"
+)
 
 var (
-	parsers = map[string]func(bytes []byte) ([]any, error){
-		"yml":  unmarshalYAML,
-		"yaml": unmarshalYAML,
-		"json": unmarshalJSON,
-		"toml": unmarshalTOML,
+	formats = map[string]Parser{
+		"yml":        ParserFunc(unmarshalYAML),
+		"yaml":       ParserFunc(unmarshalYAML),
+		"json":       ParserFunc(unmarshalJSON),
+		"jsonc":      ParserFunc(unmarshalJSONC),
+		"json5":      ParserFunc(unmarshalJSONC),
+		"toml":       ParserFunc(unmarshalTOML),
+		"env":        ParserFunc(unmarshalDotenv),
+		"ini":        ParserFunc(unmarshalINI),
+		"properties": ParserFunc(unmarshalProperties),
 	}
 
-	fileExtRe = regexp.MustCompile("\\.([^.]+)$")
+	formatsMu sync.RWMutex
 )
 
-// Loader loads configuration layers from YAML, JSON and TOML configuration files.
+// Loader loads configuration layers from YAML, JSON, TOML, dotenv, INI and Java
+// .properties configuration files.
 type Loader struct {
-	dirs []string
+	fsys        fs.FS
+	dirs        []string
+	formats     map[string]Parser
+	lenientJSON bool
+	confine     bool
+	noSymlinks  bool
+	maxFileSize int64
+	maxPerm     fs.FileMode
+	stdin       *ReaderLoader
+}
+
+// LoaderConfig is a structure with configuration parameters for the loader.
+type LoaderConfig struct {
+	// Dirs specifies a list of directories, in which the loader will search
+	// configuration files. The merge priority of loaded configuration layers
+	// depends on the order of directories. Layers loaded from rightmost
+	// directory have highest priority.
+	Dirs []string
+
+	// FS specifies a file system, in which the loader will search configuration
+	// files. If not specified, the loader uses file system of the operating
+	// system. Directories of FS must be specified as slash-separated paths
+	// without leading slash. If no directories specified, the root directory of
+	// FS is used.
+	FS fs.FS
+
+	// Formats specifies parsers of additional file formats for the loader. Map
+	// keys represents file extensions without leading dot. Parsers specified
+	// here override globally registered parsers.
+	Formats map[string]Parser
+
+	// LenientJSON enables parsing of files with .json extension in the same way
+	// as files with .jsonc and .json5 extensions.
+	LenientJSON bool
+
+	// Confine enables confinement of configuration files to the directories
+	// specified in Dirs. Patterns, that resolve outside of the directories, and
+	// files, that are symbolic links to files outside of the directories, are
+	// rejected. Use this option, if configuration locators can come from
+	// sources with lower trust, like $include directives of other layers.
+	Confine bool
+
+	// NoSymlinks disables loading of configuration files, that are symbolic
+	// links.
+	NoSymlinks bool
+
+	// MaxFileSize specifies maximum size of configuration files in bytes. If not
+	// specified, the size is not limited.
+	MaxFileSize int64
+
+	// MaxPerm specifies maximum permissions of configuration files. The loader
+	// refuses to load files, which have permission bits not included in MaxPerm.
+	// For example, with MaxPerm 0664 world-writable files are refused. If not
+	// specified, permissions are not checked.
+	MaxPerm fs.FileMode
+
+	// Stdin specifies a reader, from which configuration is loaded for the
+	// pattern "-". If not specified, os.Stdin is used.
+	Stdin io.Reader
+}
+
+// Parser is an interface for parsers of configuration file formats. Parser can
+// return several configuration layers from one file, like YAML parser does for
+// multi-document files. Parser can return ParseError to report the position of
+// the error in the data.
+type Parser interface {
+	Parse(data []byte) ([]any, error)
+}
+
+// ParserFunc type is an adapter to allow the use of ordinary functions as
+// parsers.
+type ParserFunc func(data []byte) ([]any, error)
+
+// Parse method calls f(data).
+func (f ParserFunc) Parse(data []byte) ([]any, error) {
+	return f(data)
+}
+
+// RegisterFormat method registers parser of file format for all loaders. The
+// format is identified by file extension without leading dot. Parser of already
+// registered format is replaced.
+func RegisterFormat(ext string, parser Parser) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	formats[ext] = parser
 }
 
 // NewLoader method creates new loader instance. Method accepts a list of
@@ -53,91 +259,280 @@ type Loader struct {
 // priority of loaded configuration layers depends on the order of directories.
 // Layers loaded from rightmost directory have highest priority.
 func NewLoader(dirs ...string) *Loader {
-	if len(dirs) == 0 {
-		panic(fmt.Errorf("%s: no directories specified", errPref))
+	return NewLoaderWithConfig(LoaderConfig{Dirs: dirs})
+}
+
+// NewFSLoader method creates new loader instance, that loads configuration files
+// from the file system fsys, like embed.FS. Method accepts a list of directories
+// in fsys, in which the loader will search configuration files. If no
+// directories specified, the root directory of fsys is used.
+func NewFSLoader(fsys fs.FS, dirs ...string) *Loader {
+	return NewLoaderWithConfig(LoaderConfig{FS: fsys, Dirs: dirs})
+}
+
+// NewLoaderWithConfig method creates new loader instance with specified
+// configuration parameters.
+func NewLoaderWithConfig(config LoaderConfig) *Loader {
+	if len(config.Dirs) == 0 {
+		if config.FS == nil {
+			panic(fmt.Errorf("%s: no directories specified", errPref))
+		}
+
+		config.Dirs = []string{"."}
+	}
+
+	if config.Stdin == nil {
+		config.Stdin = os.Stdin
+	}
+
+	l := &Loader{
+		fsys:        config.FS,
+		dirs:        config.Dirs,
+		formats:     config.Formats,
+		lenientJSON: config.LenientJSON,
+		confine:     config.Confine,
+		noSymlinks:  config.NoSymlinks,
+		maxFileSize: config.MaxFileSize,
+		maxPerm:     config.MaxPerm,
 	}
 
-	return &Loader{
-		dirs: dirs,
+	l.stdin = &ReaderLoader{
+		reader: config.Stdin,
+		loader: l,
 	}
+
+	return l
 }
 
-// Load method loads configuration layer from YAML, JSON and TOML configuration files.
+// Load method loads configuration layer from configuration files. The format of
+// configuration files can be specified explicitly before the pattern, like
+// "yaml:myapp/config". Otherwise the format is determined by file extension.
+// Each loaded layer is of conf.Layer type with the path of the configuration
+// file as the origin.
 func (l *Loader) Load(pattern string) ([]any, error) {
+	format, pattern := l.splitFormat(pattern)
+
+	if pattern == stdinPattern {
+		return l.loadStdin(format)
+	}
+
+	return l.load(l.dirs, format, pattern, nil)
+}
+
+// LoadRelative method loads configuration layer from configuration files like
+// Load method, but patterns started with "./" or "../" are resolved relative to
+// the directory of the configuration file specified by the origin.
+func (l *Loader) LoadRelative(pattern, origin string) ([]any, error) {
+	format, pattern := l.splitFormat(pattern)
+
+	if pattern == stdinPattern {
+		return l.loadStdin(format)
+	}
+
+	if !isRelative(pattern) {
+		return l.load(l.dirs, format, pattern, nil)
+	}
+
+	return l.load([]string{l.dir(origin)}, format, pattern, nil)
+}
+
+// loadStdin method loads configuration layers from standard input. If the
+// format is not specified, YAML is used, because YAML parser accepts JSON too.
+func (l *Loader) loadStdin(format string) ([]any, error) {
+	if format == "" {
+		format = stdinFormat
+	}
+
+	return l.stdin.Load(format)
+}
+
+func (l *Loader) splitFormat(pattern string) (string, string) {
+	if tokens := strings.SplitN(pattern, formatSep, 2); len(tokens) == 2 {
+		if _, ok := l.parser(tokens[0]); ok {
+			return tokens[0], tokens[1]
+		}
+	}
+
+	return "", pattern
+}
+
+// load method loads configuration layers from files matched by the pattern in
+// the directories. The chain contains origins of files with !include tags, that
+// led to this call.
+func (l *Loader) load(dirs []string, format, pattern string,
+	chain []string) ([]any, error) {
+
 	var allLayers []any
 
-	for _, dir := range l.dirs {
-		absPattern := filepath.Join(dir, pattern)
-		pathes, err := filepath.Glob(absPattern)
+	for _, dir := range dirs {
+		if l.confine && !l.isConfined(l.join(dir, pattern)) {
+			return nil, fmt.Errorf("%s: pattern resolves outside of configuration "+
+				"directories: %s", errPref, pattern)
+		}
+
+		pathes, err := l.glob(dir, pattern)
 
 		if err != nil {
 			return nil, fmt.Errorf("%s: %s", errPref, err)
 		}
 
 		for _, path := range pathes {
-			matches := fileExtRe.FindStringSubmatch(path)
+			ext := format
 
-			if matches == nil {
-				return nil, fmt.Errorf("%s: file extension not specified: %s",
-					errPref, path)
-			}
+			if ext == "" {
+				ext = strings.TrimPrefix(filepath.Ext(path), ".")
 
-			ext := matches[1]
-			parser, ok := parsers[ext]
+				if ext == "" {
+					return nil, fmt.Errorf("%s: file extension not specified: %s",
+						errPref, path)
+				}
+			}
 
-			if !ok {
+			if _, ok := l.parser(ext); !ok {
 				return nil, fmt.Errorf("%s: unknown file extension .%s",
 					errPref, ext)
 			}
 
-			f, err := os.Open(path)
+			err := l.checkFile(path)
 
 			if err != nil {
-				return nil, fmt.Errorf("%s: %s", errPref, err)
+				return nil, err
 			}
 
-			defer f.Close()
-			bytes, err := io.ReadAll(f)
+			bytes, err := l.readFile(path)
 
 			if err != nil {
-				return nil, fmt.Errorf("%s: %s", errPref, err)
+				return nil, fmt.Errorf("%s: %w", errPref, err)
 			}
 
-			layers, err := parser(bytes)
+			origin, err := l.origin(path)
 
 			if err != nil {
-				return nil, fmt.Errorf("%s: %s", errPref, err)
+				return nil, fmt.Errorf("%s: %w", errPref, err)
 			}
 
-			for _, layer := range layers {
-				if layer != nil {
-					allLayers = append(allLayers, layer)
+			for _, incOrigin := range chain {
+				if incOrigin == origin {
+					return nil, fmt.Errorf("%s: circular !include of file: %s",
+						errPref, origin)
 				}
 			}
+
+			layers, err := l.parse(bytes, ext, origin, chain)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, layer := range layers {
+				allLayers = append(allLayers,
+					conf.Layer{
+						Value:  layer,
+						Origin: origin,
+					},
+				)
+			}
 		}
 	}
 
 	return allLayers, nil
 }
 
+// parse method parses the data in the specified format and resolves custom YAML
+// tags relative to the origin. Nil layers are dropped.
+func (l *Loader) parse(data []byte, format, origin string,
+	chain []string) ([]any, error) {
+
+	parser, ok := l.parser(format)
+
+	if !ok {
+		return nil, fmt.Errorf("%s: unknown format: %s", errPref, format)
+	}
+
+	layers, err := parser.Parse(data)
+
+	if err != nil {
+		var parseErr *ParseError
+
+		if !errors.As(err, &parseErr) {
+			parseErr = &ParseError{Err: err}
+		}
+
+		parseErr.Path = origin
+
+		return nil, parseErr
+	}
+
+	var resLayers []any
+
+	for _, layer := range layers {
+		layer, err := l.resolveTags(layer, origin, chain)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if layer != nil {
+			resLayers = append(resLayers, layer)
+		}
+	}
+
+	return resLayers, nil
+}
+
+func (l *Loader) dir(origin string) string {
+	if l.fsys == nil {
+		return filepath.Dir(origin)
+	}
+
+	return path.Dir(origin)
+}
+
+func (l *Loader) origin(name string) (string, error) {
+	if l.fsys == nil {
+		return filepath.Abs(name)
+	}
+
+	return name, nil
+}
+
+func (l *Loader) parser(ext string) (Parser, bool) {
+	if parser, ok := l.formats[ext]; ok {
+		return parser, true
+	}
+
+	if ext == "json" && l.lenientJSON {
+		ext = "jsonc"
+	}
+
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	parser, ok := formats[ext]
+
+	return parser, ok
+}
+
 func unmarshalYAML(rawData []byte) ([]any, error) {
 	decoder := yaml.NewDecoder(bytes.NewReader(rawData))
 	var layers []any
 
 	for {
-		var layer any
-		err := decoder.Decode(&layer)
+		var node yaml.Node
+		err := decoder.Decode(&node)
 
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
 
-			return nil, err
+			return nil, yamlError(err)
 		}
 
-		if d, ok := layer.(map[any]any); ok {
-			layer = conformMap(d)
+		layer, err := decodeYAMLNode(&node)
+
+		if err != nil {
+			return nil, err
 		}
 
 		layers = append(layers, layer)
@@ -151,7 +546,7 @@ func unmarshalJSON(bytes []byte) ([]any, error) {
 	err := json.Unmarshal(bytes, &layer)
 
 	if err != nil {
-		return nil, err
+		return nil, jsonError(bytes, err)
 	}
 
 	return []any{layer}, nil
@@ -162,23 +557,8 @@ func unmarshalTOML(bytes []byte) ([]any, error) {
 	err := toml.Unmarshal(bytes, &layer)
 
 	if err != nil {
-		return nil, err
+		return nil, tomlError(bytes, err)
 	}
 
 	return []any{layer}, nil
 }
-
-func conformMap(m map[any]any) conf.M {
-	cm := make(conf.M)
-
-	for key, value := range m {
-		if v, ok := value.(map[any]any); ok {
-			value = conformMap(v)
-		}
-
-		keyStr := fmt.Sprintf("%v", key)
-		cm[keyStr] = value
-	}
-
-	return cm
-}
@@ -1,12 +1,17 @@
 package fileconf
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/iph0/conf/v2"
+	yaml "gopkg.in/yaml.v3"
 )
 
 func TestLoad(t *testing.T) {
@@ -119,6 +124,1170 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoadMounted(t *testing.T) {
+	configProc, err := NewProcessor()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tConfig, err := configProc.Load(
+		"paramX.paramXA=file:moo.toml#paramOD",
+		"paramY=file:zoo.json",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramX": conf.M{
+			"paramXA": conf.M{
+				"paramODA": "moo:valODA",
+				"paramODB": "moo:valODB",
+			},
+		},
+
+		"paramY": conf.A{
+			"zoo:valA",
+			"zoo:valB",
+		},
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
+func TestLoadHashInName(t *testing.T) {
+	configProc, err := NewProcessor()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tConfig, err := configProc.Load(
+		"file!:hash#1.yml",
+		"paramX=file!:hash#1.yml#paramH",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramH": "hash:valH",
+		"paramX": "hash:valH",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	kvParser := ParserFunc(
+		func(data []byte) ([]any, error) {
+			var layers []any
+
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				tokens := strings.SplitN(line, "=", 2)
+				layers = append(layers, conf.M{tokens[0]: tokens[1]})
+			}
+
+			return layers, nil
+		},
+	)
+
+	RegisterFormat("kv", kvParser)
+
+	fileLdr := NewLoaderWithConfig(
+		LoaderConfig{
+			Dirs: []string{"fileconf_test/etc"},
+
+			Formats: map[string]Parser{
+				"html": ParserFunc(
+					func(data []byte) ([]any, error) {
+						return []any{conf.M{"paramHA": "html"}}, nil
+					},
+				),
+			},
+		},
+	)
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"file": fileLdr,
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load(
+		"file:koo.kv",
+		"file:mar.html",
+		"file:yaml:loo",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramKA": "kv:valKA2",
+		"paramKB": "kv:valKB",
+		"paramHA": "html",
+		"paramLA": "loo:valLA",
+		"paramLB": "loo:valLB",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	t.Setenv("TEST_DOTENV_DBNAME", "")
+
+	configProc, err := NewProcessor()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tConfig, err := configProc.Load("file:dev.env")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"DB_HOST": "localhost",
+		"DB_PORT": "5432",
+		"DB_USER": "stat_#writer",
+		"DB_PASS": "pass\"word\t$$",
+		"DB_DSN":  "postgres://stat_#writer@localhost:5432",
+		"DB_NAME": "stat",
+		"DB_OPTS": "none",
+
+		"DB_REPLICA": "localhost:5432",
+
+		"LITERAL": "localhost",
+		"ESCAPED": "$DB_HOST",
+		"CERT":    "-----BEGIN-----\nline1\n-----END-----",
+		"EMPTY":   "",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v is not equal to %#v",
+			tConfig, eConfig)
+	}
+}
+
+func TestLoadLegacy(t *testing.T) {
+	configProc, err := NewProcessor()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tests := map[string]conf.M{
+		"file:legacy.ini": {
+			"name": "legacy",
+
+			"db": conf.M{
+				"host":     "localhost",
+				"port":     "5432",
+				"password": "pass;word",
+
+				"options": conf.M{
+					"charset": "utf8",
+					"query":   "SELECT 1 FROM dual",
+				},
+			},
+
+			"log": conf.M{
+				"level": "debug",
+			},
+		},
+
+		"file:legacy.properties": {
+			"app": conf.M{
+				"name": "legacy",
+			},
+
+			"db": conf.M{
+				"host":     "localhost",
+				"port":     "5432",
+				"url":      "jdbc:postgresql://localhost/stat",
+				"query":    "SELECT 1 FROM dual",
+				"greeting": "caf\u00e9\tbar",
+			},
+
+			"key with spaces": "value",
+		},
+	}
+
+	for locator, eConfig := range tests {
+		t.Run(locator,
+			func(t *testing.T) {
+				tConfig, err := configProc.Load(locator)
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if !reflect.DeepEqual(tConfig, eConfig) {
+					t.Errorf("unexpected configuration returned: %#v is not equal to %#v",
+						tConfig, eConfig)
+				}
+			},
+		)
+	}
+}
+
+func TestLoadRelaxedJSON(t *testing.T) {
+	fileLdr := NewLoaderWithConfig(
+		LoaderConfig{
+			Dirs:        []string{"fileconf_test/etc"},
+			LenientJSON: true,
+		},
+	)
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"file": fileLdr,
+			},
+		},
+	)
+
+	eConfig := conf.M{
+		"db": conf.M{
+			"host":  "localhost",
+			"port":  float64(5432),
+			"name":  "stat \"main\"",
+			"hosts": conf.A{"a", "b"},
+		},
+	}
+
+	for _, locator := range []string{"file:relaxed.jsonc", "file:relaxed.json"} {
+		t.Run(locator,
+			func(t *testing.T) {
+				tConfig, err := configProc.Load(locator)
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if !reflect.DeepEqual(tConfig, eConfig) {
+					t.Errorf("unexpected configuration returned: %#v is not equal to %#v",
+						tConfig, eConfig)
+				}
+			},
+		)
+	}
+}
+
+func TestLoadRelative(t *testing.T) {
+	configProc, err := NewProcessor()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tConfig, err := configProc.Load("file:nested/app.yml")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": "nested:valA",
+
+		"paramB": conf.M{
+			"paramBA": "inc:valBA",
+
+			"paramBB": conf.M{
+				"paramBBA": "shared:valBBA",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+
+	t.Run("origin",
+		func(t *testing.T) {
+			tLayers, err := NewLoader("fileconf_test/etc").Load("nested/shared.yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eOrigin, err := filepath.Abs("fileconf_test/etc/nested/shared.yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(tLayers) != 1 || tLayers[0].(conf.Layer).Origin != eOrigin {
+				t.Errorf("unexpected layers returned: %+v", tLayers)
+			}
+		},
+	)
+}
+
+func TestYAMLTags(t *testing.T) {
+	t.Setenv("CONF_TEST_PARAM_A", "env:valA")
+
+	RegisterYAMLTag("!upper",
+		func(node *yaml.Node) (any, error) {
+			return strings.ToUpper(node.Value), nil
+		},
+	)
+
+	configProc, err := NewProcessor()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tConfig, err := configProc.Load("file:tags/app.yml")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": "env:valA",
+		"paramC": "env:valA",
+		"paramD": "TAGS:VALD",
+
+		"paramE": conf.M{
+			"paramEA": "base:valEA",
+			"paramEB": "base:valEB",
+		},
+
+		"paramF": conf.M{
+			"paramEA": "base:valEA",
+			"paramEB": "tags:valEB",
+		},
+
+		"tls": conf.M{
+			"enabled": true,
+			"ca":      "-----BEGIN CERTIFICATE-----\nca\n-----END CERTIFICATE-----\n",
+		},
+
+		"cert": "-----BEGIN CERTIFICATE-----\nserver\n-----END CERTIFICATE-----\n",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+
+	t.Run("circular_include",
+		func(t *testing.T) {
+			_, err := configProc.Load("file:tags/loop.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "circular !include") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("missing_file",
+		func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"app.yml": {Data: []byte("paramA: valA\ncert: !file missing.pem\n")},
+			}
+
+			_, err := NewFSLoader(fsys).Load("app.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "app.yml:2: !file:") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("absolute_file_path",
+		func(t *testing.T) {
+			dir := t.TempDir()
+			confDir := filepath.Join(dir, "etc")
+			certFile := filepath.Join(dir, "cert.pem")
+
+			files := map[string]string{
+				certFile:                          "cert",
+				filepath.Join(confDir, "app.yml"): "cert: !file " + certFile + "\n",
+			}
+
+			err := os.Mkdir(confDir, 0755)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			for name, data := range files {
+				err := os.WriteFile(name, []byte(data), 0644)
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+
+			tLayers, err := NewLoader(confDir).Load("app.yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eValue := conf.M{"cert": "cert"}
+
+			if len(tLayers) != 1 ||
+				!reflect.DeepEqual(tLayers[0].(conf.Layer).Value, eValue) {
+
+				t.Errorf("unexpected layers returned: %+v", tLayers)
+			}
+
+			loader := NewLoaderWithConfig(
+				LoaderConfig{
+					Dirs:    []string{confDir},
+					Confine: true,
+				},
+			)
+
+			_, err = loader.Load("app.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"file is outside of configuration directories") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("absolute_file_path_fs",
+		func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"app.yml":      {Data: []byte("cert: !file /etc/cert.pem\n")},
+				"etc/cert.pem": {Data: []byte("cert")},
+			}
+
+			_, err := NewFSLoader(fsys).Load("app.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"absolute path is not allowed: /etc/cert.pem") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_env_tag",
+		func(t *testing.T) {
+			_, err := unmarshalYAML([]byte("paramA: !env [foo]\n"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 1, column 9: !env: value must be") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func TestYAMLAliases(t *testing.T) {
+	data := "base: &base {paramA: base:valA, paramB: base:valB}\n" +
+		"extra: &extra {paramC: extra:valC}\n" +
+		"paramX:\n  <<: [*base, *extra]\n  paramB: x:valB\n" +
+		"paramY: *extra\n"
+
+	tLayers, err := Parse([]byte(data), "yaml")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	base := conf.M{"paramA": "base:valA", "paramB": "base:valB"}
+	extra := conf.M{"paramC": "extra:valC"}
+
+	eLayers := []any{
+		conf.M{
+			"base":  base,
+			"extra": extra,
+
+			"paramX": conf.M{
+				"paramA": "base:valA",
+				"paramB": "x:valB",
+				"paramC": "extra:valC",
+			},
+
+			"paramY": extra,
+		},
+	}
+
+	if !reflect.DeepEqual(tLayers, eLayers) {
+		t.Errorf("unexpected layers returned: %+v is not equal to %+v",
+			tLayers, eLayers)
+	}
+
+	t.Run("recursive_alias",
+		func(t *testing.T) {
+			_, err := Parse([]byte("a: &a [*a]\n"), "yaml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "anchor 'a' value contains itself") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("excessive_aliasing",
+		func(t *testing.T) {
+			var b strings.Builder
+			b.WriteString("l0: &l0 [lol, lol, lol, lol, lol, lol, lol, lol, lol, lol]\n")
+
+			for i := 1; i <= 9; i++ {
+				fmt.Fprintf(&b, "l%d: &l%d [", i, i)
+
+				for j := 0; j < 10; j++ {
+					if j > 0 {
+						b.WriteString(", ")
+					}
+
+					fmt.Fprintf(&b, "*l%d", i-1)
+				}
+
+				b.WriteString("]\n")
+			}
+
+			_, err := Parse([]byte(b.String()), "yaml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "document contains excessive aliasing") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func TestLoadReader(t *testing.T) {
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"file": NewLoaderWithConfig(
+					LoaderConfig{
+						Dirs:  []string{"fileconf_test/etc"},
+						Stdin: strings.NewReader("paramA: stdin:valA\nparamB: stdin:valB\n"),
+					},
+				),
+
+				"stdin": NewReaderLoader(
+					strings.NewReader(`{"paramB": "reader:valB", "paramC": "reader:valC"}`),
+				),
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load(
+		"file:yaml:-",
+		"stdin:json",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramA": "stdin:valA",
+		"paramB": "reader:valB",
+		"paramC": "reader:valC",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+
+	t.Run("reread",
+		func(t *testing.T) {
+			tConfig, err := configProc.Load("stdin:json", "file:yaml:-")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eConfig := conf.M{
+				"paramA": "stdin:valA",
+				"paramB": "stdin:valB",
+				"paramC": "reader:valC",
+			}
+
+			if !reflect.DeepEqual(tConfig, eConfig) {
+				t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+					tConfig, eConfig)
+			}
+		},
+	)
+
+	t.Run("parse",
+		func(t *testing.T) {
+			tLayers, err := Parse([]byte("paramA = \"toml:valA\""), "toml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				map[string]any{"paramA": "toml:valA"},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %+v is not equal to %+v",
+					tLayers, eLayers)
+			}
+		},
+	)
+
+	t.Run("stdin_default_format",
+		func(t *testing.T) {
+			tConfig, err := configProc.Load("file:-")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eConfig := conf.M{
+				"paramA": "stdin:valA",
+				"paramB": "stdin:valB",
+			}
+
+			if !reflect.DeepEqual(tConfig, eConfig) {
+				t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+					tConfig, eConfig)
+			}
+		},
+	)
+
+	t.Run("filesystem_tags",
+		func(t *testing.T) {
+			tests := map[string]string{
+				"cert: !file fileconf_test/etc/tags/certs/ca.pem\n": "!file: tag",
+				"tls: !include fileconf_test/etc/tags/tls.yml\n":    "!include: tag",
+			}
+
+			for data, errStr := range tests {
+				_, err := Parse([]byte(data), "yaml")
+
+				if err == nil {
+					t.Error("no error happened")
+				} else if strings.Index(err.Error(),
+					"line 1: "+errStr+" is not allowed") == -1 {
+
+					t.Error("other error happened:", err)
+				}
+			}
+		},
+	)
+
+	t.Run("confined_stdin_tags",
+		func(t *testing.T) {
+			fileLdr := NewLoaderWithConfig(
+				LoaderConfig{
+					Dirs:    []string{"fileconf_test/etc"},
+					Confine: true,
+					Stdin: strings.NewReader(
+						"cert: !file tags/certs/ca.pem\n"),
+				},
+			)
+
+			_, err := fileLdr.Load("-")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 1: !file: tag is not allowed") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("unknown_format",
+		func(t *testing.T) {
+			_, err := ParseReader(strings.NewReader("<xml/>"), "xml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "unknown format: xml") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults/foo.yml": &fstest.MapFile{
+			Data: []byte("paramOA: default:valOA\nparamOC: default:valOC\n"),
+		},
+		"defaults/bar.json": &fstest.MapFile{
+			Data: []byte(`{"paramC": "default:valC"}`),
+		},
+	}
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"defaults": NewFSLoader(fsys, "defaults"),
+				"file":     NewLoader("fileconf_test/etc"),
+			},
+		},
+	)
+
+	tConfig, err := configProc.Load(
+		"defaults:*.*",
+		"file:moo.toml",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"paramC":  "default:valC",
+		"paramOA": "moo:valOA",
+		"paramOB": "moo:valOB",
+		"paramOC": "default:valOC",
+
+		"paramOD": conf.M{
+			"paramODA": "moo:valODA",
+			"paramODB": "moo:valODB",
+		},
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %+v is not equal to %+v",
+			tConfig, eConfig)
+	}
+
+	t.Run("root_dir",
+		func(t *testing.T) {
+			tLayers, err := NewFSLoader(fsys).Load("defaults/bar.json")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.Layer{
+					Value:  conf.M{"paramC": "default:valC"},
+					Origin: "defaults/bar.json",
+				},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %+v is not equal to %+v",
+					tLayers, eLayers)
+			}
+		},
+	)
+
+	t.Run("root_dir_glob",
+		func(t *testing.T) {
+			tLayers, err := NewFSLoader(fsys).Load("*/bar.*")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.Layer{
+					Value:  conf.M{"paramC": "default:valC"},
+					Origin: "defaults/bar.json",
+				},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %+v is not equal to %+v",
+					tLayers, eLayers)
+			}
+		},
+	)
+
+	t.Run("glob_without_extension",
+		func(t *testing.T) {
+			tLayers, err := NewFSLoader(fsys, "defaults").Load("*")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.Layer{
+					Value:  conf.M{"paramC": "default:valC"},
+					Origin: "defaults/bar.json",
+				},
+				conf.Layer{
+					Value: conf.M{
+						"paramOA": "default:valOA",
+						"paramOC": "default:valOC",
+					},
+					Origin: "defaults/foo.yml",
+				},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %+v is not equal to %+v",
+					tLayers, eLayers)
+			}
+		},
+	)
+
+	t.Run("skip_dirs",
+		func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"conf.d/foo.yml": {Data: []byte("paramA: sub:valA")},
+				"bar.yml":        {Data: []byte("paramA: root:valA")},
+			}
+
+			tLayers, err := NewFSLoader(fsys).Load("*.*")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eLayers := []any{
+				conf.Layer{
+					Value:  conf.M{"paramA": "root:valA"},
+					Origin: "bar.yml",
+				},
+			}
+
+			if !reflect.DeepEqual(tLayers, eLayers) {
+				t.Errorf("unexpected layers returned: %+v is not equal to %+v",
+					tLayers, eLayers)
+			}
+		},
+	)
+}
+
+func TestLoadRecursive(t *testing.T) {
+	eOrder := []string{"first", "nine", "base", "override", "sub"}
+
+	t.Run("os",
+		func(t *testing.T) {
+			tLayers, err := NewLoader("fileconf_test/etc").Load("conf.d/**/*.yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			tOrder := layersOrder(tLayers)
+
+			if !reflect.DeepEqual(tOrder, eOrder) {
+				t.Errorf("unexpected order of layers: %v is not equal to %v",
+					tOrder, eOrder)
+			}
+		},
+	)
+
+	t.Run("fs",
+		func(t *testing.T) {
+			fsys := fstest.MapFS{
+				"conf.d/10-base.yml":       {Data: []byte("order: base")},
+				"conf.d/100-override.yml":  {Data: []byte("order: override")},
+				"conf.d/2-first.yml":       {Data: []byte("order: first")},
+				"conf.d/9-sub/1-nine.yml":  {Data: []byte("order: nine")},
+				"conf.d/sub/50-sub.yml":    {Data: []byte("order: sub")},
+				"conf.d/sub/50-sub.kv":     {Data: []byte("order=kv")},
+				"conf.d/other/1-other.yml": {Data: []byte("order: other")},
+			}
+
+			tLayers, err := NewFSLoader(fsys).Load("conf.d/**/[0-9]*-*[a-z].yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			tOrder := layersOrder(tLayers)
+			eOrder := append([]string{}, eOrder[:4]...)
+			eOrder = append(eOrder, "other", "sub")
+
+			if !reflect.DeepEqual(tOrder, eOrder) {
+				t.Errorf("unexpected order of layers: %v is not equal to %v",
+					tOrder, eOrder)
+			}
+		},
+	)
+
+	t.Run("no_dir",
+		func(t *testing.T) {
+			tLayers, err := NewLoader("fileconf_test/etc").Load("missing/**/*.yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(tLayers) > 0 {
+				t.Errorf("unexpected layers returned: %+v", tLayers)
+			}
+		},
+	)
+}
+
+func layersOrder(layers []any) []string {
+	var order []string
+
+	for _, layer := range layers {
+		order = append(order, layer.(conf.Layer).Value.(conf.M)["order"].(string))
+	}
+
+	return order
+}
+
+func TestParseErrors(t *testing.T) {
+	loader := NewLoader("fileconf_test/etc/broken")
+
+	tests := []struct {
+		file   string
+		line   int
+		column int
+		msg    string
+	}{
+		{"broken.json", 3, 13, "invalid character 'v'"},
+		{"broken.yml", 3, 0, "mapping values are not allowed"},
+		{"broken.toml", 2, 10, "expected value"},
+		{"duplicate.yml", 2, 0, "mapping key \"paramA\" already defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file,
+			func(t *testing.T) {
+				_, err := loader.Load(tt.file)
+
+				if err == nil {
+					t.Error("no error happened")
+					return
+				}
+
+				var parseErr *ParseError
+
+				if !errors.As(err, &parseErr) {
+					t.Error("other error happened:", err)
+					return
+				}
+
+				ePath, err := filepath.Abs(filepath.Join("fileconf_test/etc/broken", tt.file))
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				if parseErr.Path != ePath || parseErr.Line != tt.line ||
+					parseErr.Column != tt.column {
+
+					t.Errorf("unexpected location of error: %s:%d:%d", parseErr.Path,
+						parseErr.Line, parseErr.Column)
+				}
+
+				if strings.Index(parseErr.Error(), tt.msg) == -1 {
+					t.Error("other error happened:", parseErr)
+				}
+			},
+		)
+	}
+}
+
+func TestSecurity(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "etc")
+	err := os.Mkdir(confDir, 0755)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	files := map[string]string{
+		filepath.Join(dir, "secret.yml"):     "paramA: secret:valA\n",
+		filepath.Join(confDir, "foo.yml"):    "paramA: foo:valA\n",
+		filepath.Join(confDir, "shared.yml"): "paramA: shared:valA\n",
+	}
+
+	for name, data := range files {
+		err := os.WriteFile(name, []byte(data), 0644)
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	err = os.Symlink(filepath.Join(dir, "secret.yml"),
+		filepath.Join(confDir, "secret.yml"))
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = os.Symlink("foo.yml", filepath.Join(confDir, "link.yml"))
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = os.Chmod(filepath.Join(confDir, "shared.yml"), 0666)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	loader := NewLoaderWithConfig(
+		LoaderConfig{
+			Dirs:        []string{confDir},
+			Confine:     true,
+			MaxFileSize: 64,
+			MaxPerm:     0644,
+		},
+	)
+
+	t.Run("confined",
+		func(t *testing.T) {
+			tLayers, err := loader.Load("link.yml")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if len(tLayers) != 1 {
+				t.Errorf("unexpected layers returned: %+v", tLayers)
+			}
+		},
+	)
+
+	t.Run("pattern_outside",
+		func(t *testing.T) {
+			_, err := loader.Load("../secret.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "pattern resolves outside") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("symlink_outside",
+		func(t *testing.T) {
+			_, err := loader.Load("secret.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "file is outside") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("no_symlinks",
+		func(t *testing.T) {
+			_, err := NewLoaderWithConfig(
+				LoaderConfig{
+					Dirs:       []string{confDir},
+					NoSymlinks: true,
+				},
+			).Load("link.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "symbolic links are not allowed") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("insecure_permissions",
+		func(t *testing.T) {
+			_, err := loader.Load("shared.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "insecure permissions") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("max_file_size",
+		func(t *testing.T) {
+			_, err := NewLoaderWithConfig(
+				LoaderConfig{
+					Dirs:        []string{confDir},
+					MaxFileSize: 8,
+				},
+			).Load("foo.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "exceeds maximum size of 8 bytes") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
 func TestPanic(t *testing.T) {
 	t.Run("no_directories",
 		func(t *testing.T) {
@@ -170,6 +1339,102 @@ func TestErrors(t *testing.T) {
 		},
 	)
 
+	t.Run("required_file_not_found",
+		func(t *testing.T) {
+			_, err := configProc.Load("file!:missing.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "no configuration layers loaded") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_dotenv",
+		func(t *testing.T) {
+			_, err := unmarshalDotenv([]byte("DB_HOST=localhost\nDB_PASS=\"pass\n"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 2: unterminated double-quoted value") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("duplicate_ini_key",
+		func(t *testing.T) {
+			_, err := unmarshalINI([]byte("[db]\nhost = foo\n\n[db]\nhost = bar\n"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 5: duplicate key db.host") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("duplicate_properties_key",
+		func(t *testing.T) {
+			_, err := unmarshalProperties([]byte("db.host=foo\ndb.host=bar\n"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 2: parameter db.host is already set") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("conflicting_properties_key",
+		func(t *testing.T) {
+			_, err := unmarshalProperties([]byte("db=foo\ndb.host=bar\n"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "parameter db.host conflicts with value of parameter db") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("strict_json",
+		func(t *testing.T) {
+			_, err := configProc.Load("file:relaxed.json")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "invalid character") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_jsonc",
+		func(t *testing.T) {
+			_, err := unmarshalJSONC([]byte("{\n  a: 1,\n  b: [1 2]\n}"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 3, column 9") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("unterminated_jsonc_comment",
+		func(t *testing.T) {
+			_, err := unmarshalJSONC([]byte("{\n  a: 1 /* comment\n}"))
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "line 2, column 8: unterminated comment") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
 	t.Run("invalid_pattern",
 		func(t *testing.T) {
 			_, err := configProc.Load("file:f[oo.yml")
@@ -181,6 +1446,18 @@ func TestErrors(t *testing.T) {
 			}
 		},
 	)
+
+	t.Run("invalid_recursive_pattern",
+		func(t *testing.T) {
+			_, err := configProc.Load("file:conf.d/**/f[oo.yml")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "syntax error in pattern") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
 }
 
 func NewProcessor() (*conf.Processor, error) {
@@ -0,0 +1,277 @@
+package fileconf
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const recursiveWildcard = "**"
+
+func (l *Loader) glob(dir, pattern string) ([]string, error) {
+	var pathes []string
+	var err error
+
+	if isRecursive(pattern) {
+		pathes, err = l.globRecursive(dir, pattern)
+	} else {
+		pathes, err = l.globFiles(dir, pattern)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pathes,
+		func(i, j int) bool {
+			return comparePathes(pathes[i], pathes[j]) < 0
+		},
+	)
+
+	return pathes, nil
+}
+
+func (l *Loader) globFiles(dir, pattern string) ([]string, error) {
+	var matches []string
+	var err error
+
+	if l.fsys == nil {
+		matches, err = filepath.Glob(filepath.Join(dir, pattern))
+	} else {
+		matches, err = fs.Glob(l.fsys, path.Join(dir, filepath.ToSlash(pattern)))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	pathes := matches[:0]
+
+	for _, name := range matches {
+		if info, err := l.stat(name); err == nil && info.IsDir() {
+			continue
+		}
+
+		pathes = append(pathes, name)
+	}
+
+	return pathes, nil
+}
+
+// globRecursive method walks the directory tree starting from the longest
+// prefix of the pattern without wildcards and returns pathes of files, that
+// match the pattern. The "**" element of the pattern matches zero or more
+// directories.
+func (l *Loader) globRecursive(dir, pattern string) ([]string, error) {
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+	var base []string
+
+	for len(elems) > 0 && !hasMeta(elems[0]) {
+		base = append(base, elems[0])
+		elems = elems[1:]
+	}
+
+	for _, elem := range elems {
+		if elem == recursiveWildcard {
+			continue
+		}
+
+		if _, err := path.Match(elem, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	var root string
+
+	if l.fsys == nil {
+		root = filepath.Join(dir, filepath.FromSlash(path.Join(base...)))
+	} else {
+		root = path.Join(dir, path.Join(base...))
+	}
+
+	var pathes []string
+
+	err := l.walkDir(root,
+		func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if name == root && errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			if entry.Type()&fs.ModeSymlink != 0 {
+				info, err := l.stat(name)
+
+				if err != nil || info.IsDir() {
+					return nil
+				}
+			}
+
+			rel := l.relPath(root, name)
+
+			if matchElems(elems, strings.Split(rel, "/")) {
+				pathes = append(pathes, name)
+			}
+
+			return nil
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pathes, nil
+}
+
+func (l *Loader) walkDir(root string, fn fs.WalkDirFunc) error {
+	if l.fsys == nil {
+		return filepath.WalkDir(root, fn)
+	}
+
+	return fs.WalkDir(l.fsys, root, fn)
+}
+
+func (l *Loader) stat(name string) (fs.FileInfo, error) {
+	if l.fsys == nil {
+		return os.Stat(name)
+	}
+
+	return fs.Stat(l.fsys, name)
+}
+
+func (l *Loader) relPath(root, name string) string {
+	if l.fsys == nil {
+		rel, _ := filepath.Rel(root, name)
+		return filepath.ToSlash(rel)
+	}
+
+	if root == "." {
+		return name
+	}
+
+	return strings.TrimPrefix(name, root+"/")
+}
+
+func matchElems(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == recursiveWildcard {
+			for i := 0; i <= len(names); i++ {
+				if matchElems(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+
+		patterns = patterns[1:]
+		names = names[1:]
+	}
+
+	return len(names) == 0
+}
+
+func isRecursive(pattern string) bool {
+	for _, elem := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if elem == recursiveWildcard {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasMeta(elem string) bool {
+	return strings.ContainsAny(elem, `*?[\`)
+}
+
+// comparePathes compares pathes element by element in natural order: elements
+// are compared lexically, but sequences of digits are compared numerically.
+func comparePathes(a, b string) int {
+	aElems := strings.Split(filepath.ToSlash(a), "/")
+	bElems := strings.Split(filepath.ToSlash(b), "/")
+
+	for i := 0; i < len(aElems) && i < len(bElems); i++ {
+		if c := compareNatural(aElems[i], bElems[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(aElems) - len(bElems)
+}
+
+func compareNatural(a, b string) int {
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			iEnd, jEnd := i, j
+
+			for iEnd < len(a) && isDigit(a[iEnd]) {
+				iEnd++
+			}
+
+			for jEnd < len(b) && isDigit(b[jEnd]) {
+				jEnd++
+			}
+
+			aNum := strings.TrimLeft(a[i:iEnd], "0")
+			bNum := strings.TrimLeft(b[j:jEnd], "0")
+
+			if len(aNum) != len(bNum) {
+				return len(aNum) - len(bNum)
+			}
+
+			if c := strings.Compare(aNum, bNum); c != 0 {
+				return c
+			}
+
+			i, j = iEnd, jEnd
+
+			continue
+		}
+
+		if a[i] != b[j] {
+			return int(a[i]) - int(b[j])
+		}
+
+		i++
+		j++
+	}
+
+	if c := (len(a) - i) - (len(b) - j); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isRelative(pattern string) bool {
+	pattern = filepath.ToSlash(pattern)
+
+	return pattern == "." || pattern == ".." ||
+		strings.HasPrefix(pattern, "./") || strings.HasPrefix(pattern, "../")
+}
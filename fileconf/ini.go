@@ -0,0 +1,135 @@
+package fileconf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/iph0/conf/v2"
+)
+
+// unmarshalINI parses files in INI format. Sections become nested maps, dotted
+// section names, like [db.connectors], become nested maps too. Lines started
+// with ";" or "#" are comments. Keys and values are separated by "=" or ":".
+// Unquoted values can be followed by a comment. Lines ended with "\" are
+// continued on the next line. Duplicate keys in the same section are errors.
+func unmarshalINI(data []byte) ([]any, error) {
+	layer := make(conf.M)
+	section := layer
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	var sectionName string
+
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimSpace(lines[i])
+
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, "\\") + strings.TrimSpace(lines[i])
+		}
+
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, iniError(lineNum, "unterminated section name")
+			}
+
+			sectionName = strings.TrimSpace(line[1 : len(line)-1])
+
+			if sectionName == "" {
+				return nil, iniError(lineNum, "empty section name")
+			}
+
+			var err error
+			section, err = getSection(layer, strings.Split(sectionName, "."))
+
+			if err != nil {
+				return nil, iniError(lineNum, err.Error())
+			}
+
+			continue
+		}
+
+		sepIdx := strings.IndexAny(line, "=:")
+
+		if sepIdx == -1 {
+			return nil, iniError(lineNum, "missing \"=\" after key")
+		}
+
+		key := strings.TrimSpace(line[:sepIdx])
+
+		if key == "" {
+			return nil, iniError(lineNum, "empty key")
+		}
+
+		value := iniValue(strings.TrimSpace(line[sepIdx+1:]))
+
+		if _, ok := section[key]; ok {
+			if sectionName != "" {
+				key = sectionName + "." + key
+			}
+
+			return nil, iniError(lineNum, "duplicate key "+key)
+		}
+
+		section[key] = value
+	}
+
+	return []any{layer}, nil
+}
+
+func iniValue(value string) string {
+	if len(value) >= 2 {
+		quote := value[0]
+
+		if (quote == '"' || quote == '\'') && value[len(value)-1] == quote {
+			return value[1 : len(value)-1]
+		}
+	}
+
+	for i := 1; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') &&
+			(value[i-1] == ' ' || value[i-1] == '\t') {
+
+			return strings.TrimSpace(value[:i])
+		}
+	}
+
+	return value
+}
+
+func getSection(m conf.M, keys []string) (conf.M, error) {
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		node, ok := m[key]
+
+		if !ok {
+			child := make(conf.M)
+			m[key] = child
+			m = child
+
+			continue
+		}
+
+		child, ok := node.(conf.M)
+
+		if !ok {
+			return nil, fmt.Errorf("section %s conflicts with value of key %s",
+				strings.Join(keys, "."), strings.Join(keys[:i+1], "."))
+		}
+
+		m = child
+	}
+
+	return m, nil
+}
+
+func iniError(line int, msg string) error {
+	return &ParseError{
+		Line: line,
+		Err:  errors.New(msg),
+	}
+}
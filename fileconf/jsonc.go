@@ -0,0 +1,287 @@
+package fileconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// unmarshalJSONC parses JSON files with extensions from JSONC and JSON5
+// formats: single-line and multi-line comments, trailing commas, unquoted keys
+// and single-quoted strings. The data is converted to strict JSON before
+// parsing, syntax errors are reported with line and column in the source data.
+func unmarshalJSONC(data []byte) ([]any, error) {
+	strict, offsets, err := normalizeJSON(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var layer any
+	err = json.Unmarshal(strict, &layer)
+
+	if err != nil {
+		var offset int64
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		} else if errors.As(err, &typeErr) {
+			offset = typeErr.Offset
+		} else {
+			return nil, err
+		}
+
+		srcOffset := len(data)
+
+		if offset > 0 && int(offset) <= len(offsets) {
+			srcOffset = offsets[offset-1]
+		}
+
+		return nil, offsetError(data, srcOffset, err)
+	}
+
+	return []any{layer}, nil
+}
+
+// normalizeJSON converts relaxed JSON to strict JSON and returns the offsets of
+// source bytes for each byte of the result.
+func normalizeJSON(data []byte) ([]byte, []int, error) {
+	n := &jsonNormalizer{
+		data: data,
+		out:  make([]byte, 0, len(data)),
+	}
+
+	err := n.normalize()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return n.out, n.offsets, nil
+}
+
+type jsonNormalizer struct {
+	data    []byte
+	out     []byte
+	offsets []int
+}
+
+func (n *jsonNormalizer) normalize() error {
+	data := n.data
+
+	for i := 0; i < len(data); {
+		c := data[i]
+
+		switch {
+		case c == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*'):
+			end, err := n.skipComment(i)
+
+			if err != nil {
+				return err
+			}
+
+			i = end
+		case c == '"':
+			end, err := n.copyString(i)
+
+			if err != nil {
+				return err
+			}
+
+			i = end
+		case c == '\'':
+			end, err := n.convertString(i)
+
+			if err != nil {
+				return err
+			}
+
+			i = end
+		case c == ',':
+			next, err := n.nextSignificant(i + 1)
+
+			if err != nil {
+				return err
+			}
+
+			if next < len(data) && (data[next] == '}' || data[next] == ']') {
+				i++
+				continue
+			}
+
+			n.emit(c, i)
+			i++
+		case isIdentStart(c):
+			end := i + 1
+
+			for end < len(data) && isIdentPart(data[end]) {
+				end++
+			}
+
+			next, err := n.nextSignificant(end)
+
+			if err != nil {
+				return err
+			}
+
+			quote := next < len(data) && data[next] == ':'
+
+			if quote {
+				n.emit('"', i)
+			}
+
+			for j := i; j < end; j++ {
+				n.emit(data[j], j)
+			}
+
+			if quote {
+				n.emit('"', end-1)
+			}
+
+			i = end
+		default:
+			n.emit(c, i)
+			i++
+		}
+	}
+
+	return nil
+}
+
+func (n *jsonNormalizer) skipComment(start int) (int, error) {
+	data := n.data
+
+	if data[start+1] == '/' {
+		end := bytes.IndexByte(data[start:], '\n')
+
+		if end == -1 {
+			return len(data), nil
+		}
+
+		return start + end, nil
+	}
+
+	end := bytes.Index(data[start+2:], []byte("*/"))
+
+	if end == -1 {
+		return 0, jsoncError(data, start, "unterminated comment")
+	}
+
+	n.emit(' ', start)
+
+	return start + 2 + end + 2, nil
+}
+
+func (n *jsonNormalizer) copyString(start int) (int, error) {
+	data := n.data
+	n.emit('"', start)
+
+	for i := start + 1; i < len(data); i++ {
+		c := data[i]
+		n.emit(c, i)
+
+		if c == '\\' && i+1 < len(data) {
+			i++
+			n.emit(data[i], i)
+		} else if c == '"' {
+			return i + 1, nil
+		}
+	}
+
+	return 0, jsoncError(data, start, "unterminated string")
+}
+
+func (n *jsonNormalizer) convertString(start int) (int, error) {
+	data := n.data
+	n.emit('"', start)
+
+	for i := start + 1; i < len(data); i++ {
+		c := data[i]
+
+		switch c {
+		case '\\':
+			if i+1 < len(data) && data[i+1] == '\'' {
+				i++
+				n.emit('\'', i)
+			} else if i+1 < len(data) {
+				n.emit(c, i)
+				i++
+				n.emit(data[i], i)
+			}
+		case '"':
+			n.emit('\\', i)
+			n.emit('"', i)
+		case '\'':
+			n.emit('"', i)
+			return i + 1, nil
+		default:
+			n.emit(c, i)
+		}
+	}
+
+	return 0, jsoncError(data, start, "unterminated string")
+}
+
+func (n *jsonNormalizer) nextSignificant(i int) (int, error) {
+	data := n.data
+
+	for i < len(data) {
+		c := data[i]
+
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			i++
+		} else if c == '/' && i+1 < len(data) && (data[i+1] == '/' || data[i+1] == '*') {
+			if data[i+1] == '/' {
+				end := bytes.IndexByte(data[i:], '\n')
+
+				if end == -1 {
+					return len(data), nil
+				}
+
+				i += end
+			} else {
+				end := bytes.Index(data[i+2:], []byte("*/"))
+
+				if end == -1 {
+					return 0, jsoncError(data, i, "unterminated comment")
+				}
+
+				i += 2 + end + 2
+			}
+		} else {
+			break
+		}
+	}
+
+	return i, nil
+}
+
+func (n *jsonNormalizer) emit(c byte, offset int) {
+	n.out = append(n.out, c)
+	n.offsets = append(n.offsets, offset)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func jsoncError(data []byte, offset int, msg string) error {
+	return offsetError(data, offset, errors.New(msg))
+}
+
+func position(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	line := bytes.Count(data[:offset], []byte("\n")) + 1
+	col := offset - bytes.LastIndexByte(data[:offset], '\n')
+
+	return line, col
+}
@@ -0,0 +1,161 @@
+package fileconf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iph0/conf/v2"
+	"github.com/iph0/conf/v2/internal/params"
+)
+
+// unmarshalProperties parses files in Java .properties format. Dotted keys,
+// like db.host, become nested maps. Lines started with "#" or "!" are
+// comments. Keys and values are separated by "=", ":" or whitespace. Lines
+// ended with odd number of "\" are continued on the next line. Escape sequences
+// \t, \n, \r, \f, \uXXXX are supported in keys and values. Duplicate keys are
+// errors.
+func unmarshalProperties(data []byte) ([]any, error) {
+	layer := make(conf.M)
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		for isContinued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		if isContinued(line) {
+			line = line[:len(line)-1]
+		}
+
+		key, value := splitProperty(line)
+
+		key, err := unescapeProperty(key)
+
+		if err != nil {
+			return nil, propertiesError(lineNum, err.Error())
+		}
+
+		value, err = unescapeProperty(value)
+
+		if err != nil {
+			return nil, propertiesError(lineNum, err.Error())
+		}
+
+		if key == "" {
+			return nil, propertiesError(lineNum, "empty key")
+		}
+
+		err = params.Set(layer, strings.Split(key, "."), value, false)
+
+		if err != nil {
+			return nil, propertiesError(lineNum, err.Error())
+		}
+	}
+
+	return []any{layer}, nil
+}
+
+func isContinued(line string) bool {
+	var n int
+
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+func splitProperty(line string) (string, string) {
+	i := 0
+
+	for ; i < len(line); i++ {
+		c := line[i]
+
+		if c == '\\' {
+			i++
+			continue
+		}
+
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+	}
+
+	if i >= len(line) {
+		return line, ""
+	}
+
+	key := line[:i]
+	rest := strings.TrimLeft(line[i:], " \t\f")
+
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	return key, rest
+}
+
+func unescapeProperty(str string) (string, error) {
+	if !strings.Contains(str, "\\") {
+		return str, nil
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+
+		if c != '\\' || i+1 >= len(str) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+
+		switch str[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(str) {
+				return "", fmt.Errorf("invalid unicode escape sequence")
+			}
+
+			code, err := strconv.ParseUint(str[i+1:i+5], 16, 16)
+
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape sequence \\u%s",
+					str[i+1:i+5])
+			}
+
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			b.WriteByte(str[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+func propertiesError(line int, msg string) error {
+	return &ParseError{
+		Line: line,
+		Err:  errors.New(msg),
+	}
+}
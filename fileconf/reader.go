@@ -0,0 +1,83 @@
+package fileconf
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+const (
+	stdinPattern = "-"
+	stdinFormat  = "yaml"
+)
+
+// ReaderLoader loads configuration layers from io.Reader, like standard input.
+// Configuration locators for this loader are names of formats, like "yaml" or
+// "json". The data is read once at first loading and is reused by subsequent
+// loadings.
+type ReaderLoader struct {
+	reader io.Reader
+	loader *Loader
+	mu     sync.Mutex
+	data   []byte
+	err    error
+	read   bool
+}
+
+// NewReaderLoader method creates new loader instance, that loads configuration
+// layers from the reader. Parsers registered for all loaders are used.
+func NewReaderLoader(r io.Reader) *ReaderLoader {
+	return &ReaderLoader{
+		reader: r,
+		loader: &Loader{},
+	}
+}
+
+// Load method loads configuration layers from the data of the reader in the
+// specified format. The !include and !file YAML tags are not allowed in the data.
+func (l *ReaderLoader) Load(format string) ([]any, error) {
+	if format == "" {
+		return nil, fmt.Errorf("%s: format of configuration data not specified",
+			errPref)
+	}
+
+	data, err := l.readAll()
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPref, err)
+	}
+
+	return l.loader.parse(data, format, "", nil)
+}
+
+func (l *ReaderLoader) readAll() ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.read {
+		l.data, l.err = io.ReadAll(l.reader)
+		l.read = true
+	}
+
+	return l.data, l.err
+}
+
+// Parse method parses configuration layers from the data in the specified
+// format, like "yaml" or "json", using parsers registered for all loaders. The
+// !include and !file YAML tags are not allowed in the data, so untrusted data
+// can not read local files.
+func Parse(data []byte, format string) ([]any, error) {
+	return (&Loader{}).parse(data, format, "", nil)
+}
+
+// ParseReader method reads all data from the reader and parses configuration
+// layers from it in the specified format.
+func ParseReader(r io.Reader, format string) ([]any, error) {
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPref, err)
+	}
+
+	return Parse(data, format)
+}
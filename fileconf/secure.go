@@ -0,0 +1,164 @@
+package fileconf
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func (l *Loader) checkFile(name string) error {
+	if l.noSymlinks {
+		var info fs.FileInfo
+		var err error
+
+		if l.fsys == nil {
+			info, err = os.Lstat(name)
+		} else {
+			info, err = fs.Stat(l.fsys, name)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", errPref, err)
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%s: symbolic links are not allowed: %s", errPref,
+				name)
+		}
+	}
+
+	if l.confine {
+		resolved := name
+
+		if l.fsys == nil {
+			var err error
+			resolved, err = filepath.EvalSymlinks(name)
+
+			if err != nil {
+				return fmt.Errorf("%s: %w", errPref, err)
+			}
+		}
+
+		if !l.isConfined(resolved) {
+			return fmt.Errorf("%s: file is outside of configuration directories: %s",
+				errPref, name)
+		}
+	}
+
+	if l.maxPerm != 0 {
+		info, err := l.stat(name)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", errPref, err)
+		}
+
+		if perm := info.Mode().Perm(); perm&^l.maxPerm != 0 {
+			return fmt.Errorf("%s: insecure permissions %s of file: %s", errPref,
+				perm, name)
+		}
+	}
+
+	return nil
+}
+
+// isConfined method checks, that the path is inside one of configuration
+// directories of the loader. Symbolic links in directory pathes are resolved.
+func (l *Loader) isConfined(name string) bool {
+	if l.fsys != nil {
+		name = path.Clean(name)
+
+		for _, dir := range l.dirs {
+			dir = path.Clean(dir)
+
+			if dir == "." && name != ".." && !strings.HasPrefix(name, "../") ||
+				name == dir || strings.HasPrefix(name, dir+"/") {
+
+				return true
+			}
+		}
+
+		return false
+	}
+
+	name, err := filepath.Abs(name)
+
+	if err != nil {
+		return false
+	}
+
+	for _, dir := range l.dirs {
+		dir, err := filepath.Abs(dir)
+
+		if err != nil {
+			continue
+		}
+
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil &&
+			isSubpath(resolved, name) {
+
+			return true
+		}
+
+		if isSubpath(dir, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *Loader) join(dir, pattern string) string {
+	if l.fsys == nil {
+		return filepath.Join(dir, pattern)
+	}
+
+	return path.Join(dir, filepath.ToSlash(pattern))
+}
+
+func (l *Loader) readFile(name string) ([]byte, error) {
+	var file fs.File
+	var err error
+
+	if l.fsys == nil {
+		file, err = os.Open(name)
+	} else {
+		file, err = l.fsys.Open(name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	if l.maxFileSize <= 0 {
+		return io.ReadAll(file)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, l.maxFileSize+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > l.maxFileSize {
+		return nil, fmt.Errorf("file exceeds maximum size of %d bytes: %s",
+			l.maxFileSize, name)
+	}
+
+	return data, nil
+}
+
+func isSubpath(dir, name string) bool {
+	rel, err := filepath.Rel(dir, name)
+
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,467 @@
+package fileconf
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+
+	"github.com/iph0/conf/v2"
+	"github.com/iph0/merger"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	mergeTag    = "!!merge"
+	noOriginMsg = "tag is not allowed in data, that is not loaded from a file"
+)
+
+// YAMLTagFunc is a function, that converts YAML node with a custom tag to a
+// configuration value.
+type YAMLTagFunc func(node *yaml.Node) (any, error)
+
+var (
+	yamlTags = map[string]YAMLTagFunc{
+		"!env":     envTag,
+		"!ref":     refTag,
+		"!include": includeTag,
+		"!file":    fileTag,
+	}
+
+	yamlTagsMu sync.RWMutex
+)
+
+// includeRef is a placeholder for a value of !include tag, that is resolved by
+// the loader relative to the including file.
+type includeRef struct {
+	patterns []string
+	line     int
+}
+
+// fileRef is a placeholder for a value of !file tag, that is resolved by the
+// loader relative to the including file.
+type fileRef struct {
+	path string
+	line int
+}
+
+// RegisterYAMLTag method registers a function, that converts YAML nodes with
+// the specified tag, like "!secret", to configuration values. The function of
+// already registered tag is replaced.
+func RegisterYAMLTag(tag string, f YAMLTagFunc) {
+	yamlTagsMu.Lock()
+	defer yamlTagsMu.Unlock()
+
+	yamlTags[tag] = f
+}
+
+func yamlTag(tag string) (YAMLTagFunc, bool) {
+	yamlTagsMu.RLock()
+	defer yamlTagsMu.RUnlock()
+
+	f, ok := yamlTags[tag]
+
+	return f, ok
+}
+
+// yamlDecoder converts YAML nodes to configuration values. Like the decoder of
+// yaml.v3 package, it rejects recursive aliases and documents with excessive
+// aliasing, like "billion laughs" documents.
+type yamlDecoder struct {
+	aliases     map[*yaml.Node]bool
+	decodeCount int
+	aliasCount  int
+	aliasDepth  int
+}
+
+// decodeYAMLNode converts YAML node to a configuration value applying
+// functions of custom tags.
+func decodeYAMLNode(node *yaml.Node) (any, error) {
+	d := &yamlDecoder{
+		aliases: make(map[*yaml.Node]bool),
+	}
+
+	return d.decode(node)
+}
+
+func (d *yamlDecoder) decode(node *yaml.Node) (any, error) {
+	d.decodeCount++
+
+	if d.aliasDepth > 0 {
+		d.aliasCount++
+	}
+
+	if d.aliasCount > 100 && d.decodeCount > 1000 &&
+		float64(d.aliasCount)/float64(d.decodeCount) >
+			allowedAliasRatio(d.decodeCount) {
+
+		return nil, &ParseError{
+			Line:   node.Line,
+			Column: node.Column,
+			Err:    errors.New("document contains excessive aliasing"),
+		}
+	}
+
+	if f, ok := yamlTag(node.Tag); ok {
+		value, err := f(node)
+
+		if err != nil {
+			var parseErr *ParseError
+
+			if errors.As(err, &parseErr) {
+				return nil, err
+			}
+
+			return nil, &ParseError{
+				Line:   node.Line,
+				Column: node.Column,
+				Err:    fmt.Errorf("%s: %w", node.Tag, err),
+			}
+		}
+
+		return value, nil
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+
+		return d.decode(node.Content[0])
+	case yaml.AliasNode:
+		return d.decodeAlias(node)
+	case yaml.MappingNode:
+		m := make(conf.M)
+		err := d.decodeMapping(node, m, make(map[string]int))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return m, nil
+	case yaml.SequenceNode:
+		s := make(conf.A, 0, len(node.Content))
+
+		for _, child := range node.Content {
+			value, err := d.decode(child)
+
+			if err != nil {
+				return nil, err
+			}
+
+			s = append(s, value)
+		}
+
+		return s, nil
+	}
+
+	var value any
+	err := node.Decode(&value)
+
+	if err != nil {
+		return nil, yamlError(err)
+	}
+
+	return value, nil
+}
+
+// decodeAlias method decodes the node referenced by the alias. An alias to the
+// node, which is being decoded, is rejected.
+func (d *yamlDecoder) decodeAlias(node *yaml.Node) (any, error) {
+	if d.aliases[node.Alias] {
+		return nil, &ParseError{
+			Line:   node.Line,
+			Column: node.Column,
+			Err:    fmt.Errorf("anchor '%s' value contains itself", node.Value),
+		}
+	}
+
+	d.aliases[node.Alias] = true
+	d.aliasDepth++
+
+	defer func() {
+		delete(d.aliases, node.Alias)
+		d.aliasDepth--
+	}()
+
+	return d.decode(node.Alias)
+}
+
+func (d *yamlDecoder) decodeMapping(node *yaml.Node, m conf.M,
+	lines map[string]int) error {
+
+	var merges []*yaml.Node
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+
+		if keyNode.Tag == mergeTag {
+			merges = append(merges, valueNode)
+			continue
+		}
+
+		key, err := d.decode(keyNode)
+
+		if err != nil {
+			return err
+		}
+
+		keyStr := fmt.Sprintf("%v", key)
+
+		if line, ok := lines[keyStr]; ok {
+			return &ParseError{
+				Line: keyNode.Line,
+				Err: fmt.Errorf("mapping key %q already defined at line %d",
+					keyStr, line),
+			}
+		}
+
+		lines[keyStr] = keyNode.Line
+
+		value, err := d.decode(valueNode)
+
+		if err != nil {
+			return err
+		}
+
+		m[keyStr] = value
+	}
+
+	for _, merge := range merges {
+		value, err := d.decode(merge)
+
+		if err != nil {
+			return err
+		}
+
+		var sources []any
+
+		switch v := value.(type) {
+		case conf.M:
+			sources = []any{v}
+		case conf.A:
+			sources = v
+		default:
+			return mergeError(merge)
+		}
+
+		for _, source := range sources {
+			merged, ok := source.(conf.M)
+
+			if !ok {
+				return mergeError(merge)
+			}
+
+			for key, value := range merged {
+				if _, ok := m[key]; !ok {
+					m[key] = value
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func mergeError(node *yaml.Node) error {
+	return &ParseError{
+		Line: node.Line,
+		Err:  errors.New("map merge requires map or sequence of maps as the value"),
+	}
+}
+
+// allowedAliasRatio returns the maximum ratio of nodes decoded through aliases
+// to all decoded nodes, like in yaml.v3 package. Small documents can use
+// aliases freely, large documents must mostly consist of regular nodes.
+func allowedAliasRatio(decodeCount int) float64 {
+	switch {
+	case decodeCount <= 400000:
+		return 0.99
+	case decodeCount >= 4000000:
+		return 0.10
+	}
+
+	return 0.99 - 0.89*(float64(decodeCount-400000)/3600000)
+}
+
+func envTag(node *yaml.Node) (any, error) {
+	if node.Kind != yaml.ScalarNode {
+		return nil, errors.New("value must be a name of environment variable")
+	}
+
+	value, ok := os.LookupEnv(node.Value)
+
+	if !ok {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+func refTag(node *yaml.Node) (any, error) {
+	value, err := decodeUntagged(node)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return conf.M{"$ref": value}, nil
+}
+
+func includeTag(node *yaml.Node) (any, error) {
+	ref := &includeRef{
+		line: node.Line,
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		ref.patterns = []string{node.Value}
+	case yaml.SequenceNode:
+		for _, child := range node.Content {
+			if child.Kind != yaml.ScalarNode {
+				return nil, errors.New("value must be a pattern or a list of patterns")
+			}
+
+			ref.patterns = append(ref.patterns, child.Value)
+		}
+	default:
+		return nil, errors.New("value must be a pattern or a list of patterns")
+	}
+
+	return ref, nil
+}
+
+func fileTag(node *yaml.Node) (any, error) {
+	if node.Kind != yaml.ScalarNode {
+		return nil, errors.New("value must be a path of the file")
+	}
+
+	return &fileRef{
+		path: node.Value,
+		line: node.Line,
+	}, nil
+}
+
+// decodeUntagged converts the node to a configuration value ignoring the tag
+// of the node. Custom tags of child nodes are not applied.
+func decodeUntagged(node *yaml.Node) (any, error) {
+	untagged := *node
+	untagged.Tag = ""
+
+	if untagged.Kind == yaml.ScalarNode {
+		untagged.Tag = "!!str"
+	}
+
+	var value any
+	err := untagged.Decode(&value)
+
+	if err != nil {
+		return nil, yamlError(err)
+	}
+
+	return value, nil
+}
+
+// resolveTags method replaces placeholders of !include and !file tags in the
+// layer loaded from the file with the origin. If the origin is empty, like for
+// data parsed from memory or standard input, these tags are not allowed,
+// because there is no directory to resolve them against.
+func (l *Loader) resolveTags(value any, origin string, chain []string) (any, error) {
+	switch v := value.(type) {
+	case conf.M:
+		for key, node := range v {
+			node, err := l.resolveTags(node, origin, chain)
+
+			if err != nil {
+				return nil, err
+			}
+
+			v[key] = node
+		}
+	case conf.A:
+		for i, node := range v {
+			node, err := l.resolveTags(node, origin, chain)
+
+			if err != nil {
+				return nil, err
+			}
+
+			v[i] = node
+		}
+	case *includeRef:
+		if origin == "" {
+			return nil, &ParseError{
+				Line: v.line,
+				Err:  errors.New("!include: " + noOriginMsg),
+			}
+		}
+
+		var config any
+		dir := l.dir(origin)
+		chain = append(chain[:len(chain):len(chain)], origin)
+
+		for _, pattern := range v.patterns {
+			format, pattern := l.splitFormat(pattern)
+			layers, err := l.load([]string{dir}, format, pattern, chain)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, layer := range layers {
+				config = merger.Merge(config, layer.(conf.Layer).Value)
+			}
+		}
+
+		return config, nil
+	case *fileRef:
+		if origin == "" {
+			return nil, &ParseError{
+				Line: v.line,
+				Err:  errors.New("!file: " + noOriginMsg),
+			}
+		}
+
+		name, err := l.refPath(origin, v.path)
+
+		if err == nil {
+			err = l.checkFile(name)
+		}
+
+		if err == nil {
+			var data []byte
+			data, err = l.readFile(name)
+
+			if err == nil {
+				return string(data), nil
+			}
+		}
+
+		return nil, &ParseError{
+			Path: origin,
+			Line: v.line,
+			Err:  fmt.Errorf("!file: %w", err),
+		}
+	}
+
+	return value, nil
+}
+
+// refPath method returns the path of the file referenced by !file tag. Relative
+// pathes are resolved against the directory of the including file. Absolute
+// pathes are used as is, but are not allowed in fs.FS.
+func (l *Loader) refPath(origin, name string) (string, error) {
+	if l.fsys == nil {
+		if filepath.IsAbs(name) {
+			return name, nil
+		}
+	} else if path.IsAbs(filepath.ToSlash(name)) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("absolute path is not allowed: %s", name)
+	}
+
+	return l.join(l.dir(origin), name), nil
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2024, Eugene Ponizovsky, <ponizovsky@gmail.com>. All rights
+// reserved. Use of this source code is governed by a MIT License that can
+// be found in the LICENSE file.
+
+/*
+Package flagconf is configuration loader for the conf package. It loads
+configuration layers from command-line flags. The loader registers repeatable
+flags --set, --set-file and --config in the flag set:
+
+	myapp --config file:extra.yml \
+		--set db.connectors.main.port=5433 \
+		--set-file tls.cert=./cert.pem
+
+Flag --set overrides the parameter by the path, where names of nested
+parameters are separated by dots, like in $ref directive. Values are converted
+to integers, floats, booleans and nil, if the conversion is lossless, so values
+like "0755" or "1.10" remain strings. Values enclosed in double or single quotes
+are always strings. Flag --set-file overrides the parameter by the contents of
+the file. Flag --config specifies the additional configuration locator. The
+loader does not load these locators itself, they are returned by Locators
+method and must be passed to the processor by the application. If flags
+override the same parameter, the last flag wins. A flag can not set a value of
+the parameter, which has nested parameters set by other flags, and vice versa.
+
+Configuration locators for this loader are empty. Typically the locator is
+specified last, so that command-line flags have the highest priority, and
+locators of --config flags are specified right before it:
+
+	flagLdr := flagconf.NewLoader(flag.CommandLine)
+	flag.Parse()
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"file":  fileconf.NewLoader("etc"),
+				"flags": flagLdr,
+			},
+		},
+	)
+
+	locators := []any{"file:myapp.yml"}
+	locators = append(locators, flagLdr.Locators()...)
+	locators = append(locators, "flags:")
+
+	config, err := configProc.Load(locators...)
+
+If the application does not use the flag package, the loader can be created by
+NewLoaderFromArgs function from raw arguments, like os.Args[1:]. Arguments of
+other flags are ignored.
+*/
+package flagconf
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iph0/conf/v2"
+	"github.com/iph0/conf/v2/internal/params"
+)
+
+const (
+	errPref    = "flagconf"
+	keySep     = "."
+	assignSep  = "="
+	argsEndTag = "--"
+
+	setFlag     = "set"
+	setFileFlag = "set-file"
+	configFlag  = "config"
+)
+
+// Loader loads configuration layers from command-line flags.
+type Loader struct {
+	assigns []assignment
+	configs []any
+	err     error
+}
+
+type assignment struct {
+	flag  string
+	keys  []string
+	value string
+}
+
+// flagValue implements flag.Value interface for flags of the loader.
+type flagValue struct {
+	loader *Loader
+	name   string
+}
+
+// NewLoader method creates new loader instance and registers flags --set,
+// --set-file and --config in the flag set. If the flag set is nil,
+// flag.CommandLine is used.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	l := &Loader{}
+
+	fs.Var(&flagValue{l, setFlag}, setFlag,
+		"override configuration parameter, like db.port=5432")
+	fs.Var(&flagValue{l, setFileFlag}, setFileFlag,
+		"override configuration parameter by file contents, like tls.cert=cert.pem")
+	fs.Var(&flagValue{l, configFlag}, configFlag,
+		"load additional configuration layers, like file:extra.yml")
+
+	return l
+}
+
+// NewLoaderFromArgs method creates new loader instance from raw command-line
+// arguments. Flags --set, --set-file and --config are extracted from arguments
+// until the "--" argument, other arguments are ignored. Errors of arguments are
+// returned by Load method.
+func NewLoaderFromArgs(args []string) *Loader {
+	l := &Loader{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == argsEndTag {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		name, value, hasValue := strings.Cut(name, assignSep)
+
+		if name != setFlag && name != setFileFlag && name != configFlag {
+			continue
+		}
+
+		if !hasValue {
+			if i+1 == len(args) {
+				l.err = fmt.Errorf("%s: flag needs an argument: %s", errPref, arg)
+				return l
+			}
+
+			i++
+			value = args[i]
+		}
+
+		err := l.add(name, value)
+
+		if err != nil {
+			l.err = fmt.Errorf("%s: invalid value %q for flag %s: %s", errPref,
+				value, arg, err)
+			return l
+		}
+	}
+
+	return l
+}
+
+// Locators method returns configuration locators specified by --config flags
+// in order of their appearance.
+func (l *Loader) Locators() []any {
+	locators := make([]any, len(l.configs))
+	copy(locators, l.configs)
+
+	return locators
+}
+
+// Load method loads the configuration layer from --set and --set-file flags.
+// Locators of --config flags are not loaded, see Locators method.
+func (l *Loader) Load(locator string) ([]any, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	if locator != "" {
+		return nil, fmt.Errorf("%s: unexpected configuration locator: %s",
+			errPref, locator)
+	}
+
+	if len(l.assigns) == 0 {
+		return nil, nil
+	}
+
+	layer := make(conf.M)
+
+	for _, assign := range l.assigns {
+		var value any
+
+		if assign.flag == setFileFlag {
+			data, err := os.ReadFile(assign.value)
+
+			if err != nil {
+				return nil, fmt.Errorf("%s: can't read file for parameter %s: %s",
+					errPref, strings.Join(assign.keys, keySep), err)
+			}
+
+			value = string(data)
+		} else {
+			value = scalar(assign.value)
+		}
+
+		err := params.Set(layer, assign.keys, value, true)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", errPref, err)
+		}
+	}
+
+	return []any{layer}, nil
+}
+
+func (l *Loader) add(name, value string) error {
+	if name == configFlag {
+		if value == "" {
+			return errors.New("empty configuration locator")
+		}
+
+		l.configs = append(l.configs, value)
+
+		return nil
+	}
+
+	path, value, ok := strings.Cut(value, assignSep)
+
+	if !ok {
+		return errors.New("value must be in form path=value")
+	}
+
+	keys := strings.Split(path, keySep)
+
+	for _, key := range keys {
+		if key == "" {
+			return fmt.Errorf("invalid parameter path: %s", path)
+		}
+	}
+
+	l.assigns = append(l.assigns,
+		assignment{
+			flag:  name,
+			keys:  keys,
+			value: value,
+		},
+	)
+
+	return nil
+}
+
+// String method returns empty string, because values of flags are accumulated
+// by the loader.
+func (v *flagValue) String() string {
+	return ""
+}
+
+// Set method adds the value of the flag to the loader.
+func (v *flagValue) Set(value string) error {
+	return v.loader.add(v.name, value)
+}
+
+// scalar converts the value of --set flag to a scalar. Values enclosed in
+// quotes are always strings.
+func scalar(str string) any {
+	if len(str) >= 2 && (str[0] == '"' || str[0] == '\'') &&
+		str[len(str)-1] == str[0] {
+
+		return str[1 : len(str)-1]
+	}
+
+	return params.Scalar(str)
+}
@@ -0,0 +1,312 @@
+package flagconf
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+const testCert = "-----BEGIN CERTIFICATE-----\nMIIBtest\n" +
+	"-----END CERTIFICATE-----\n"
+
+func TestLoad(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagLdr := NewLoader(fs)
+
+	err := fs.Parse(
+		[]string{
+			"--config", "map:extra",
+			"--set", "db.connectors.main.port=5433",
+			"--set=db.connectors.main.debug=true",
+			"-set", "db.connectors.main.timeout=1.5",
+			"--set", "db.connectors.main.dbname='5432'",
+			"--set", "app.version=1.10",
+			"--set", "app.mode=0755",
+			"--set-file", "tls.cert=flagconf_test/cert.pem",
+			"extra_arg",
+		},
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	locators := []any{"map:default"}
+	locators = append(locators, flagLdr.Locators()...)
+	locators = append(locators, "flags:")
+
+	configProc := NewProcessor(flagLdr, false)
+	tConfig, err := configProc.Load(locators...)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"db": conf.M{
+			"connectors": conf.M{
+				"main": conf.M{
+					"host":    "localhost",
+					"port":    5433,
+					"dbname":  "5432",
+					"debug":   true,
+					"timeout": 1.5,
+				},
+				"stat": conf.M{
+					"host": "localhost",
+				},
+			},
+		},
+		"app": conf.M{
+			"version": "1.10",
+			"mode":    "0755",
+		},
+		"tls": conf.M{
+			"cert": testCert,
+		},
+		"log_level": "debug",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v", tConfig)
+	}
+
+	if args := fs.Args(); !reflect.DeepEqual(args, []string{"extra_arg"}) {
+		t.Errorf("unexpected arguments returned: %#v", args)
+	}
+
+	t.Run("disable_processing",
+		func(t *testing.T) {
+			configProc := NewProcessor(flagLdr, true)
+			tConfig, err := configProc.Load("flags:")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			eConfig := conf.M{
+				"db": conf.M{
+					"connectors": conf.M{
+						"main": conf.M{
+							"port":    5433,
+							"dbname":  "5432",
+							"debug":   true,
+							"timeout": 1.5,
+						},
+					},
+				},
+				"app": conf.M{
+					"version": "1.10",
+					"mode":    "0755",
+				},
+				"tls": conf.M{
+					"cert": testCert,
+				},
+			}
+
+			if !reflect.DeepEqual(tConfig, eConfig) {
+				t.Errorf("unexpected configuration returned: %#v", tConfig)
+			}
+		},
+	)
+}
+
+func TestLoadFromArgs(t *testing.T) {
+	flagLdr := NewLoaderFromArgs(
+		[]string{
+			"-v",
+			"--set", "db.connectors.main.host=db.example.com",
+			"--verbose=true",
+			"--set", "db.connectors.main.host=replica.example.com",
+			"--set", "db.connectors.main.port=6432",
+			"--",
+			"--set", "log_level=info",
+		},
+	)
+
+	configProc := NewProcessor(flagLdr, false)
+	tConfig, err := configProc.Load("map:default", "flags:")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"db": conf.M{
+			"connectors": conf.M{
+				"main": conf.M{
+					"host":   "replica.example.com",
+					"port":   6432,
+					"dbname": "stat",
+				},
+				"stat": conf.M{
+					"host": "localhost",
+				},
+			},
+		},
+		"log_level": "warn",
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v", tConfig)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Run("invalid_assignment",
+		func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			NewLoader(fs)
+
+			err := fs.Parse([]string{"--set", "db.port"})
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"value must be in form path=value") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("invalid_path",
+		func(t *testing.T) {
+			flagLdr := NewLoaderFromArgs([]string{"--set=db..port=5432"})
+			_, err := flagLdr.Load("")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"invalid parameter path: db..port") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("missing_argument",
+		func(t *testing.T) {
+			flagLdr := NewLoaderFromArgs([]string{"--config"})
+			_, err := flagLdr.Load("")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"flag needs an argument: --config") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("missing_file",
+		func(t *testing.T) {
+			flagLdr := NewLoaderFromArgs(
+				[]string{"--set-file", "tls.cert=flagconf_test/missing.pem"})
+			_, err := flagLdr.Load("")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"can't read file for parameter tls.cert") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("conflicting_parameters",
+		func(t *testing.T) {
+			flagLdr := NewLoaderFromArgs(
+				[]string{"--set", "db.port=5432", "--set", "db=stat"})
+			_, err := flagLdr.Load("")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"value of parameter db conflicts with its nested parameters") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("unexpected_locator",
+		func(t *testing.T) {
+			flagLdr := NewLoaderFromArgs(nil)
+			_, err := flagLdr.Load("foo")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"unexpected configuration locator: foo") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func NewProcessor(flagLdr *Loader, disableProcessing bool) *conf.Processor {
+	mapLdr := &mapLoader{
+		m: conf.M{
+			"default": conf.M{
+				"db": conf.M{
+					"connectors": conf.M{
+						"main": conf.M{
+							"host":   "localhost",
+							"port":   5432,
+							"dbname": "stat",
+						},
+						"stat": conf.M{
+							"host": "localhost",
+						},
+					},
+				},
+				"log_level": "warn",
+			},
+
+			"extra": conf.M{
+				"db": conf.M{
+					"connectors": conf.M{
+						"main": conf.M{
+							"debug": false,
+						},
+					},
+				},
+				"log_level": "debug",
+			},
+		},
+	}
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"map":   mapLdr,
+				"flags": flagLdr,
+			},
+			DisableProcessing: disableProcessing,
+		},
+	)
+
+	return configProc
+}
+
+type mapLoader struct {
+	m conf.M
+}
+
+// Load method loads configuration layer from a map.
+func (l *mapLoader) Load(key string) ([]any, error) {
+	return []any{l.m[key]}, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2024, Eugene Ponizovsky, <ponizovsky@gmail.com>. All rights
+// reserved. Use of this source code is governed by a MIT License that can
+// be found in the LICENSE file.
+
+// Package params contains helpers, that are shared by configuration loaders to
+// build configuration layers from flat parameter names and string values.
+package params
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iph0/conf/v2"
+)
+
+const keySep = "."
+
+// Set sets the value of the parameter by the path in the section, creating
+// nested sections if needed. An error is returned, if the path passes through
+// a value of other parameter, or the parameter already has nested parameters.
+// If the parameter already has a value, the value is replaced, if replace is
+// true, otherwise an error is returned.
+func Set(m conf.M, keys []string, value any, replace bool) error {
+	lastIdx := len(keys) - 1
+
+	for i, key := range keys[:lastIdx] {
+		node, ok := m[key]
+
+		if !ok {
+			child := make(conf.M)
+			m[key] = child
+			m = child
+
+			continue
+		}
+
+		child, ok := node.(conf.M)
+
+		if !ok {
+			return fmt.Errorf("parameter %s conflicts with value of parameter %s",
+				strings.Join(keys, keySep), strings.Join(keys[:i+1], keySep))
+		}
+
+		m = child
+	}
+
+	key := keys[lastIdx]
+	node, ok := m[key]
+
+	if ok {
+		if _, ok := node.(conf.M); ok {
+			return fmt.Errorf("value of parameter %s conflicts with its nested "+
+				"parameters", strings.Join(keys, keySep))
+		}
+
+		if !replace {
+			return fmt.Errorf("parameter %s is already set",
+				strings.Join(keys, keySep))
+		}
+	}
+
+	m[key] = value
+
+	return nil
+}
+
+// Scalar converts the string to an integer, a float, a boolean or nil, if
+// possible. Values "true" and "false" become booleans, value "null" becomes
+// nil. Numbers are converted only if the conversion is lossless, so strings
+// like "0755" or "1.10" remain strings.
+func Scalar(str string) any {
+	switch str {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		if strconv.FormatInt(i, 10) == str {
+			return int(i)
+		}
+
+		return str
+	}
+
+	if !strings.ContainsAny(str, "0123456789") {
+		return str
+	}
+
+	if f, err := strconv.ParseFloat(str, 64); err == nil {
+		if strconv.FormatFloat(f, 'f', -1, 64) == str {
+			return f
+		}
+	}
+
+	return str
+}
@@ -0,0 +1,107 @@
+package params
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+func TestSet(t *testing.T) {
+	m := conf.M{}
+
+	for _, path := range []string{"db.host", "db.port", "db.port", "name"} {
+		err := Set(m, strings.Split(path, "."), path, true)
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	eM := conf.M{
+		"db": conf.M{
+			"host": "db.host",
+			"port": "db.port",
+		},
+		"name": "name",
+	}
+
+	if !reflect.DeepEqual(m, eM) {
+		t.Errorf("unexpected section returned: %#v", m)
+	}
+
+	t.Run("already_set",
+		func(t *testing.T) {
+			err := Set(m, []string{"name"}, "foo", false)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "parameter name is already set") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("value_in_path",
+		func(t *testing.T) {
+			err := Set(m, []string{"name", "first"}, "foo", true)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"parameter name.first conflicts with value of parameter name") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("nested_parameters",
+		func(t *testing.T) {
+			err := Set(m, []string{"db"}, "foo", true)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"value of parameter db conflicts with its nested parameters") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func TestScalar(t *testing.T) {
+	tests := map[string]any{
+		"true":                  true,
+		"false":                 false,
+		"null":                  nil,
+		"5432":                  5432,
+		"-1":                    -1,
+		"0":                     0,
+		"0.5":                   0.5,
+		"-2.25":                 -2.25,
+		"0755":                  "0755",
+		"1.10":                  "1.10",
+		"1.0":                   "1.0",
+		"+1":                    "+1",
+		"1e3":                   "1e3",
+		"1_000":                 "1_000",
+		"0x1F":                  "0x1F",
+		"NaN":                   "NaN",
+		"Inf":                   "Inf",
+		"1.2.3":                 "1.2.3",
+		"stat":                  "stat",
+		"":                      "",
+		"True":                  "True",
+		"999999999999999999999": "999999999999999999999",
+	}
+
+	for str, eValue := range tests {
+		if tValue := Scalar(str); !reflect.DeepEqual(tValue, eValue) {
+			t.Errorf("unexpected value returned for %q: %#v", str, tValue)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const redacted = "***"
+
+// Secret type represents sensitive configuration value, like a password or
+// a token. Secret values are printed as "***" by the fmt package and are
+// redacted by encoders, so they do not leak into logs and dumps. Secret values
+// can be created by $secret directive, and can be used as a field type in
+// configuration structures. To get the actual value convert it to a string.
+type Secret string
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// String method returns redacted value of the secret.
+func (s Secret) String() string {
+	return redacted
+}
+
+// GoString method returns redacted value of the secret.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("conf.Secret(%q)", redacted)
+}
+
+// MarshalText method returns redacted value of the secret. This method is used
+// by encoders of JSON, YAML and TOML formats.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// Redact function returns a copy of the configuration tree, in which all
+// secret values are replaced by "***" string.
+func Redact(tree any) any {
+	switch t := tree.(type) {
+	case M:
+		m := make(M, len(t))
+
+		for key, value := range t {
+			m[key] = Redact(value)
+		}
+
+		return m
+	case A:
+		s := make(A, len(t))
+
+		for i, value := range t {
+			s[i] = Redact(value)
+		}
+
+		return s
+	case Secret:
+		return redacted
+	}
+
+	return tree
+}
+
+func markSecret(node any) any {
+	switch n := node.(type) {
+	case nil, Secret:
+		return n
+	case string:
+		return Secret(n)
+	case M:
+		m := make(M, len(n))
+
+		for key, value := range n {
+			m[key] = markSecret(value)
+		}
+
+		return m
+	case A:
+		s := make(A, len(n))
+
+		for i, value := range n {
+			s[i] = markSecret(value)
+		}
+
+		return s
+	}
+
+	return Secret(fmt.Sprintf("%v", node))
+}
+
+func isSecret(node reflect.Value) bool {
+	return node.IsValid() && node.Type() == secretType
+}
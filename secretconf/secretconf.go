@@ -0,0 +1,141 @@
+// Copyright (c) 2024, Eugene Ponizovsky, <ponizovsky@gmail.com>. All rights
+// reserved. Use of this source code is governed by a MIT License that can
+// be found in the LICENSE file.
+
+/*
+Package secretconf is configuration loader for the conf package. It loads
+configuration layers from directories, where each file contains one value, like
+Docker secrets or systemd credentials. File names become parameter names and
+file contents with trimmed trailing newlines become values. All loaded values
+are of conf.Secret type. Configuration locators for this loader are directory
+paths, that can contain references to environment variables. Here some examples:
+
+	secret:/run/secrets
+	secret:$CREDENTIALS_DIRECTORY
+
+Hidden files and subdirectories are skipped. If the directory does not exist,
+no configuration layers are loaded.
+*/
+package secretconf
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/iph0/conf/v2"
+	"github.com/iph0/conf/v2/internal/params"
+)
+
+const errPref = "secretconf"
+
+// DefaultMaxPerm is default maximum permissions of secret files.
+const DefaultMaxPerm fs.FileMode = 0644
+
+// Loader loads configuration layers from directories with secret files.
+type Loader struct {
+	config LoaderConfig
+}
+
+// LoaderConfig is a structure with configuration parameters for the loader.
+type LoaderConfig struct {
+	// Separator specifies a separator of nested parameter names in file names.
+	// For example, with separator "__" the file "db__password" becomes the
+	// parameter "password" in the section "db". If not specified, file names
+	// are used as parameter names as is.
+	Separator string
+
+	// MaxPerm specifies maximum permissions of secret files. The loader refuses
+	// to load files, which have permission bits not included in MaxPerm. If not
+	// specified, DefaultMaxPerm is used.
+	MaxPerm fs.FileMode
+}
+
+// NewLoader method creates new loader instance with default configuration
+// parameters.
+func NewLoader() *Loader {
+	return NewLoaderWithConfig(LoaderConfig{})
+}
+
+// NewLoaderWithConfig method creates new loader instance with specified
+// configuration parameters.
+func NewLoaderWithConfig(config LoaderConfig) *Loader {
+	if config.MaxPerm == 0 {
+		config.MaxPerm = DefaultMaxPerm
+	}
+
+	return &Loader{
+		config: config,
+	}
+}
+
+// Load method loads configuration layer from a directory with secret files.
+func (l *Loader) Load(dir string) ([]any, error) {
+	dir = os.ExpandEnv(dir)
+
+	if dir == "" {
+		return nil, fmt.Errorf("%s: no directory specified", errPref)
+	}
+
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%s: %w", errPref, err)
+	}
+
+	layer := make(conf.M)
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errPref, err)
+		}
+
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		if perm := info.Mode().Perm(); perm&^l.config.MaxPerm != 0 {
+			return nil, fmt.Errorf("%s: insecure permissions %s of file: %s",
+				errPref, perm, path)
+		}
+
+		data, err := os.ReadFile(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errPref, err)
+		}
+
+		value := conf.Secret(strings.TrimRight(string(data), "\r\n"))
+
+		var keys []string
+
+		if l.config.Separator != "" {
+			keys = strings.Split(name, l.config.Separator)
+		} else {
+			keys = []string{name}
+		}
+
+		err = params.Set(layer, keys, value, false)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %s", errPref, err, path)
+		}
+	}
+
+	return []any{layer}, nil
+}
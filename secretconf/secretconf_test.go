@@ -0,0 +1,166 @@
+package secretconf
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+func TestLoad(t *testing.T) {
+	configProc := NewProcessor(LoaderConfig{Separator: "__"})
+
+	tConfig, err := configProc.Load(
+		"map:default",
+		"secret:secretconf_test/secrets",
+		"secret:secretconf_test/missing",
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"db": conf.M{
+			"host":     "localhost",
+			"username": conf.Secret("stat_writer"),
+			"password": conf.Secret("stat_writer_pass"),
+		},
+
+		"api_token": conf.Secret("tokenA"),
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v", tConfig)
+	}
+}
+
+func TestLoadFlat(t *testing.T) {
+	t.Setenv("TEST_SECRETS_DIR", "secretconf_test/secrets")
+	configProc := NewProcessor(LoaderConfig{})
+
+	tConfig, err := configProc.Load("secret:$TEST_SECRETS_DIR")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	eConfig := conf.M{
+		"db__username": conf.Secret("stat_writer"),
+		"db__password": conf.Secret("stat_writer_pass"),
+		"api_token":    conf.Secret("tokenA"),
+	}
+
+	if !reflect.DeepEqual(tConfig, eConfig) {
+		t.Errorf("unexpected configuration returned: %#v", tConfig)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	configProc := NewProcessor(LoaderConfig{Separator: "__"})
+
+	t.Run("no_directory",
+		func(t *testing.T) {
+			_, err := configProc.Load("secret:")
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "no directory specified") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("insecure_permissions",
+		func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "password")
+			err := os.WriteFile(path, []byte("pass"), 0600)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			err = os.Chmod(path, 0666)
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			_, err = configProc.Load("secret:" + dir)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "insecure permissions") == -1 {
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+
+	t.Run("conflicting_names",
+		func(t *testing.T) {
+			dir := t.TempDir()
+
+			for _, name := range []string{"db", "db__password"} {
+				err := os.WriteFile(filepath.Join(dir, name), []byte("pass"), 0600)
+
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+
+			_, err := configProc.Load("secret:" + dir)
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(),
+				"parameter db.password conflicts with value of parameter db") == -1 {
+
+				t.Error("other error happened:", err)
+			}
+		},
+	)
+}
+
+func NewProcessor(config LoaderConfig) *conf.Processor {
+	mapLdr := &mapLoader{
+		m: conf.M{
+			"default": conf.M{
+				"db": conf.M{
+					"host":     "localhost",
+					"username": "guest",
+				},
+			},
+		},
+	}
+
+	secretLdr := NewLoaderWithConfig(config)
+
+	configProc := conf.NewProcessor(
+		conf.ProcessorConfig{
+			Loaders: map[string]conf.Loader{
+				"map":    mapLdr,
+				"secret": secretLdr,
+			},
+		},
+	)
+
+	return configProc
+}
+
+type mapLoader struct {
+	m conf.M
+}
+
+// Load method loads configuration layer from a map.
+func (l *mapLoader) Load(key string) ([]any, error) {
+	return []any{l.m[key]}, nil
+}
@@ -0,0 +1,84 @@
+package conf
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Value type is a holder of decoded configuration structure of type T, that
+// can be replaced on configuration reload. Readers always get completely
+// decoded and validated structure. The zero value is ready to use and holds
+// nil until the first successful update.
+type Value[T any] struct {
+	current     atomic.Pointer[T]
+	mu          sync.Mutex
+	subscribers []func(old, new *T)
+}
+
+// Validator is an interface for configuration structures, that can validate
+// themselves. If a pointer to decoded structure implements Validator, Value
+// calls Validate method before replacing the current structure.
+type Validator interface {
+	Validate() error
+}
+
+// Load method returns current configuration structure. Returned structure must
+// not be modified.
+func (v *Value[T]) Load() *T {
+	return v.current.Load()
+}
+
+// Subscribe method registers a function, that is called after each replacement
+// of configuration structure with old and new structures. Functions are called
+// synchronously in the order of registration and must not call Update or Reload
+// methods.
+func (v *Value[T]) Subscribe(f func(old, new *T)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.subscribers = append(v.subscribers, f)
+}
+
+// Update method decodes raw configuration data into new structure, validates it
+// and replaces the current structure. If decoding or validation fails, the
+// current structure is kept and error is returned.
+func (v *Value[T]) Update(configRaw M) error {
+	config := new(T)
+	err := Decode(configRaw, config)
+
+	if err != nil {
+		return err
+	}
+
+	if validator, ok := any(config).(Validator); ok {
+		err := validator.Validate()
+
+		if err != nil {
+			return fmt.Errorf("%s: invalid configuration: %w", errPref, err)
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	old := v.current.Swap(config)
+
+	for _, f := range v.subscribers {
+		f(old, config)
+	}
+
+	return nil
+}
+
+// Reload method loads configuration tree with configuration processor and
+// updates the current structure using Update method.
+func (v *Value[T]) Reload(p *Processor, locators ...any) error {
+	configRaw, err := p.Load(locators...)
+
+	if err != nil {
+		return err
+	}
+
+	return v.Update(configRaw)
+}
@@ -0,0 +1,131 @@
+package conf_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/iph0/conf/v2"
+)
+
+type testDBConfig struct {
+	Host string
+	Port int
+}
+
+func (c *testDBConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+
+	return nil
+}
+
+func TestValue(t *testing.T) {
+	var value conf.Value[testDBConfig]
+
+	if value.Load() != nil {
+		t.Error("unexpected value returned before update")
+		return
+	}
+
+	var changes [][2]*testDBConfig
+
+	value.Subscribe(
+		func(old, new *testDBConfig) {
+			changes = append(changes, [2]*testDBConfig{old, new})
+		},
+	)
+
+	configProc := conf.NewProcessor(conf.ProcessorConfig{})
+
+	err := value.Reload(configProc,
+		conf.M{
+			"host": "localhost",
+			"port": "5432",
+		},
+	)
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	first := value.Load()
+
+	if first == nil || *first != (testDBConfig{Host: "localhost", Port: 5432}) {
+		t.Errorf("unexpected value returned: %+v", first)
+		return
+	}
+
+	t.Run("invalid_update",
+		func(t *testing.T) {
+			err := value.Update(conf.M{"host": "stat-master.mydb.com", "port": 0})
+
+			if err == nil {
+				t.Error("no error happened")
+			} else if strings.Index(err.Error(), "port must be positive") == -1 {
+				t.Error("other error happened:", err)
+			}
+
+			err = value.Update(conf.M{"port": "foo"})
+
+			if err == nil {
+				t.Error("no error happened")
+			}
+
+			if value.Load() != first {
+				t.Errorf("current value replaced: %+v", value.Load())
+			}
+		},
+	)
+
+	t.Run("update",
+		func(t *testing.T) {
+			err := value.Update(conf.M{"host": "stat-master.mydb.com", "port": 5433})
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			second := value.Load()
+
+			if *second != (testDBConfig{Host: "stat-master.mydb.com", Port: 5433}) {
+				t.Errorf("unexpected value returned: %+v", second)
+			}
+
+			if len(changes) != 2 ||
+				changes[0][0] != nil || changes[0][1] != first ||
+				changes[1][0] != first || changes[1][1] != second {
+
+				t.Errorf("unexpected changes: %+v", changes)
+			}
+		},
+	)
+}
+
+func TestValueConcurrency(t *testing.T) {
+	var value conf.Value[testDBConfig]
+	var wg sync.WaitGroup
+
+	for i := 1; i <= 10; i++ {
+		wg.Add(2)
+
+		go func(port int) {
+			defer wg.Done()
+			value.Update(conf.M{"host": "localhost", "port": port})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			if config := value.Load(); config != nil && config.Host != "localhost" {
+				t.Errorf("unexpected value returned: %+v", config)
+			}
+		}()
+	}
+
+	wg.Wait()
+}